@@ -0,0 +1,131 @@
+package audio
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/chinese-room-solutions/fakebro/internal/random"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnsupportedPlatform = fmt.Errorf("unsupported platform")
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Profile holds the AudioContext values a page observes.
+type Profile struct {
+	// SampleSum is the sum of the absolute samples 4500 to 5000 rendered by an OfflineAudioContext
+	// for a 10 kHz triangle oscillator through a dynamics compressor.
+	SampleSum       float64
+	SampleRate      int
+	BaseLatency     float64
+	MaxChannelCount int
+}
+
+type audioProfile struct {
+	Browser          string                     `yaml:"browser"`
+	Platforms        []string                   `yaml:"platforms"`
+	Arch             string                     `yaml:"arch"`
+	SampleSums       []random.Weighted[float64] `yaml:"sample_sums"`
+	SampleRates      []random.Weighted[int]     `yaml:"sample_rates"`
+	LatencyFrames    int                        `yaml:"latency_frames"`
+	LatencyMS        int                        `yaml:"latency_ms"`
+	MaxChannelCounts []random.Weighted[int]     `yaml:"max_channel_counts"`
+}
+
+type audioData struct {
+	Profiles []audioProfile `yaml:"profiles"`
+}
+
+var data audioData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for i, p := range data.Profiles {
+		if len(p.SampleSums) == 0 || len(p.SampleRates) == 0 || len(p.MaxChannelCounts) == 0 {
+			panic(fmt.Sprintf("Incomplete audio profile %d in data.yml", i))
+		}
+	}
+}
+
+type options struct {
+	Arch    string
+	Browser string
+}
+
+type Option func(*options)
+
+// WithArch selects values for the given sec-ch-ua-arch value, e.g. "arm" or "x86".
+func WithArch(arch string) Option {
+	return func(o *options) {
+		o.Arch = arch
+	}
+}
+
+// WithBrowser sets the browser the values are reported by: "Chrome", "Firefox" or "Safari".
+// Other browsers are treated as Chromium. Without it, Chrome is assumed.
+func WithBrowser(name string) Option {
+	return func(o *options) {
+		o.Browser = name
+	}
+}
+
+func (p *audioProfile) matches(platform string, o options) bool {
+	if p.Browser != "" && p.Browser != o.Browser {
+		return false
+	}
+	if p.Arch != "" && !strings.EqualFold(p.Arch, o.Arch) {
+		return false
+	}
+	return slices.ContainsFunc(p.Platforms, func(name string) bool {
+		return strings.EqualFold(name, platform)
+	})
+}
+
+// GenerateProfile generates the AudioContext values of a browser on the platform.
+// The same seed always produces the same values.
+func GenerateProfile(seed int64, platform string, opts ...Option) (*Profile, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.Browser = family.Of(o.Browser)
+
+	i := slices.IndexFunc(data.Profiles, func(p audioProfile) bool {
+		return p.matches(platform, o)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s with %s", ErrUnsupportedPlatform, platform, o.Browser)
+	}
+	p := data.Profiles[i]
+
+	r := rand.New(rand.NewSource(seed))
+	profile := &Profile{
+		SampleSum:       random.Pick(r, p.SampleSums, random.WeightOf[float64]).Value,
+		SampleRate:      random.Pick(r, p.SampleRates, random.WeightOf[int]).Value,
+		MaxChannelCount: random.Pick(r, p.MaxChannelCounts, random.WeightOf[int]).Value,
+	}
+
+	switch {
+	case p.LatencyMS > 0:
+		profile.BaseLatency = float64(p.LatencyMS) / 1000
+	case p.LatencyFrames > 0:
+		profile.BaseLatency = float64(p.LatencyFrames) / float64(profile.SampleRate)
+	}
+
+	return profile, nil
+}
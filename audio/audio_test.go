@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name                string
+		platform            string
+		opts                []Option
+		expectedSampleSums  []float64
+		expectedSampleRates []int
+		expectedLatency     func(sampleRate int) float64
+		expectedError       error
+	}{
+		{
+			name:                "Chrome on Windows",
+			platform:            "Windows",
+			expectedSampleSums:  []float64{124.04347527516074, 124.04347657808103, 124.0434474653739},
+			expectedSampleRates: []int{48000, 44100},
+			expectedLatency:     func(int) float64 { return 0.01 },
+		},
+		{
+			name:                "Chrome on Apple silicon",
+			platform:            "macOS",
+			opts:                []Option{WithBrowser("Chrome"), WithArch("arm")},
+			expectedSampleSums:  []float64{124.04347730590962, 124.04347527516074},
+			expectedSampleRates: []int{48000, 44100},
+			expectedLatency:     func(rate int) float64 { return 256 / float64(rate) },
+		},
+		{
+			name:                "Edge on Linux",
+			platform:            "linux",
+			opts:                []Option{WithBrowser("Edge")},
+			expectedSampleSums:  []float64{124.04347527516074, 124.04347657808103},
+			expectedSampleRates: []int{48000, 44100},
+			expectedLatency:     func(rate int) float64 { return 256 / float64(rate) },
+		},
+		{
+			name:                "Firefox on Windows",
+			platform:            "Windows",
+			opts:                []Option{WithBrowser("Firefox")},
+			expectedSampleSums:  []float64{35.749968223273754},
+			expectedSampleRates: []int{48000, 44100},
+			expectedLatency:     func(int) float64 { return 0.01 },
+		},
+		{
+			name:                "Safari on macOS",
+			platform:            "macOS",
+			opts:                []Option{WithBrowser("Safari")},
+			expectedSampleSums:  []float64{35.10893253237009},
+			expectedSampleRates: []int{48000, 44100},
+			expectedLatency:     func(int) float64 { return 0 },
+		},
+		{
+			name:                "Chrome on iOS",
+			platform:            "iOS",
+			expectedSampleSums:  []float64{35.10893253237009, 35.10892717540264},
+			expectedSampleRates: []int{48000},
+			expectedLatency:     func(int) float64 { return 0 },
+		},
+		{
+			name:          "Safari on Windows",
+			platform:      "Windows",
+			opts:          []Option{WithBrowser("Safari")},
+			expectedError: ErrUnsupportedPlatform,
+		},
+		{
+			name:          "unsupported platform",
+			platform:      "PlayStation",
+			expectedError: ErrUnsupportedPlatform,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				profile, err := GenerateProfile(seed, tt.platform, tt.opts...)
+				if tt.expectedError != nil {
+					require.ErrorIs(t, err, tt.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Contains(t, tt.expectedSampleSums, profile.SampleSum)
+				require.Contains(t, tt.expectedSampleRates, profile.SampleRate)
+				require.InDelta(t, tt.expectedLatency(profile.SampleRate), profile.BaseLatency, 1e-12)
+				require.Contains(t, []int{2, 6, 8}, profile.MaxChannelCount)
+			}
+		})
+	}
+}
+
+func TestGenerateProfileDeterministic(t *testing.T) {
+	profile1, err := GenerateProfile(12345, "Windows")
+	require.NoError(t, err)
+	profile2, err := GenerateProfile(12345, "Windows")
+	require.NoError(t, err)
+	require.Equal(t, profile1, profile2)
+}
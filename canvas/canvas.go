@@ -0,0 +1,102 @@
+package canvas
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/webgl"
+)
+
+var ErrUnsupportedPlatform = fmt.Errorf("unsupported platform")
+
+// Profile describes how the canvas of an identity renders and is read back.
+type Profile struct {
+	// Accelerated reports whether 2D canvases are GPU rasterized. Software renderers and
+	// virtual machine adapters fall back to CPU rasterization.
+	Accelerated bool
+	Noise       Noise
+	// PixelHashes maps the name of each reference drawing to the hash of its pixels with the
+	// noise applied, see Drawing.PixelHash.
+	PixelHashes map[string]string
+}
+
+// noiseRange bounds the noise generated for the platforms of a rasterizer family.
+type noiseRange struct {
+	platforms    []string
+	maxIntensity int
+	minDensity   float64
+	maxDensity   float64
+}
+
+// High density screens hide smaller perturbations, mobile GPUs show more variation between devices.
+var noiseRanges = []noiseRange{
+	{platforms: []string{"windows"}, maxIntensity: 2, minDensity: 0.02, maxDensity: 0.06},
+	{platforms: []string{"macos", "ios", "ipados"}, maxIntensity: 1, minDensity: 0.01, maxDensity: 0.03},
+	{platforms: []string{"linux", "chrome os", "chromeos"}, maxIntensity: 2, minDensity: 0.02, maxDensity: 0.05},
+	{platforms: []string{"android"}, maxIntensity: 3, minDensity: 0.03, maxDensity: 0.08},
+}
+
+type options struct {
+	GPU *webgl.GPU
+}
+
+type Option func(*options)
+
+// WithGPU ties the profile to the GPU of the identity, e.g. the one returned by webgl.GenerateGPU.
+// Identities sharing a seed but not a GPU get different noise.
+func WithGPU(gpu webgl.GPU) Option {
+	return func(o *options) {
+		o.GPU = &gpu
+	}
+}
+
+// GenerateProfile generates the canvas profile of an identity. The same seed, platform and GPU
+// always produce the same profile.
+func GenerateProfile(seed int64, platform string, opts ...Option) (*Profile, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	platform = strings.ToLower(platform)
+	var nr *noiseRange
+	for i := range noiseRanges {
+		for _, p := range noiseRanges[i].platforms {
+			if p == platform {
+				nr = &noiseRanges[i]
+			}
+		}
+	}
+	if nr == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(platform))
+	accelerated := true
+	if o.GPU != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(o.GPU.Renderer))
+		accelerated = !o.GPU.IsVirtual
+	}
+
+	r := rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+	noise := Noise{
+		Seed:      r.Uint64(),
+		Intensity: 1 + r.Intn(nr.maxIntensity),
+		Density:   nr.minDensity + r.Float64()*(nr.maxDensity-nr.minDensity),
+	}
+
+	hashes := make(map[string]string, len(ReferenceDrawings))
+	for _, d := range ReferenceDrawings {
+		hashes[d.Name] = d.PixelHash(noise)
+	}
+
+	return &Profile{
+		Accelerated: accelerated,
+		Noise:       noise,
+		PixelHashes: hashes,
+	}, nil
+}
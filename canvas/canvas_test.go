@@ -0,0 +1,83 @@
+package canvas
+
+import (
+	"testing"
+
+	"github.com/chinese-room-solutions/fakebro/webgl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name                string
+		platform            string
+		opts                []Option
+		expectedAccelerated bool
+		expectedIntensity   int
+		expectedError       error
+	}{
+		{
+			name:                "Windows",
+			platform:            "Windows",
+			opts:                []Option{WithGPU(webgl.GPU{Vendor: "nvidia", Renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090 Direct3D11 vs_5_0 ps_5_0, D3D11)"})},
+			expectedAccelerated: true,
+			expectedIntensity:   2,
+		},
+		{
+			name:                "macOS",
+			platform:            "macOS",
+			expectedAccelerated: true,
+			expectedIntensity:   1,
+		},
+		{
+			name:                "software renderer",
+			platform:            "Linux",
+			opts:                []Option{WithGPU(webgl.GPU{Vendor: "mesa", Renderer: "ANGLE (Mesa/X.org, llvmpipe (LLVM 15.0.6 256 bits), OpenGL 4.5)", IsVirtual: true})},
+			expectedAccelerated: false,
+			expectedIntensity:   2,
+		},
+		{
+			name:          "unsupported platform",
+			platform:      "PlayStation",
+			expectedError: ErrUnsupportedPlatform,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				profile, err := GenerateProfile(seed, tt.platform, tt.opts...)
+				if tt.expectedError != nil {
+					require.ErrorIs(t, err, tt.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedAccelerated, profile.Accelerated)
+				require.GreaterOrEqual(t, profile.Noise.Intensity, 1)
+				require.LessOrEqual(t, profile.Noise.Intensity, tt.expectedIntensity)
+				require.Positive(t, profile.Noise.Density)
+				require.Len(t, profile.PixelHashes, len(ReferenceDrawings))
+			}
+		})
+	}
+}
+
+func TestGenerateProfileDeterministic(t *testing.T) {
+	gpu, err := webgl.GenerateGPU(42, "Windows", "15.0.0")
+	require.NoError(t, err)
+
+	profile1, err := GenerateProfile(42, "Windows", WithGPU(gpu))
+	require.NoError(t, err)
+	profile2, err := GenerateProfile(42, "Windows", WithGPU(gpu))
+	require.NoError(t, err)
+	require.Equal(t, profile1, profile2)
+
+	other, err := GenerateProfile(43, "Windows", WithGPU(gpu))
+	require.NoError(t, err)
+	require.NotEqual(t, profile1.PixelHashes, other.PixelHashes)
+
+	gpu.Renderer += " "
+	otherGPU, err := GenerateProfile(42, "Windows", WithGPU(gpu))
+	require.NoError(t, err)
+	require.NotEqual(t, profile1.Noise, otherGPU.Noise)
+}
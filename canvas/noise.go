@@ -0,0 +1,144 @@
+package canvas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+)
+
+// Noise describes the perturbation applied to the pixels read back from a canvas.
+//
+// For the pixel at index i (row-major, starting at 0), let h = splitmix64(Seed + i).
+// Fully transparent pixels are left alone. Otherwise the pixel is perturbed when
+// h&0xffff < floor(Density*65536): channel (h>>16)%3 (red, green, blue) changes by
+// 1 + (h>>24)%Intensity, downwards when bit 32 of h is set, clamped to [0, 255].
+// The algorithm only uses 64-bit integer arithmetic so it can be reproduced in the page.
+type Noise struct {
+	Seed      uint64
+	Intensity int
+	Density   float64
+}
+
+// splitmix64 returns the output of the SplitMix64 generator for the given state.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// Apply perturbs the image in place.
+func (n Noise) Apply(img *image.RGBA) {
+	if n.Intensity <= 0 || n.Density <= 0 {
+		return
+	}
+
+	threshold := uint64(n.Density * 65536)
+	bounds := img.Bounds()
+	i := uint64(0)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			h := splitmix64(n.Seed + i)
+			i++
+
+			offset := img.PixOffset(x, y)
+			if img.Pix[offset+3] == 0 || h&0xffff >= threshold {
+				continue
+			}
+
+			channel := offset + int((h>>16)%3)
+			delta := 1 + int((h>>24)%uint64(n.Intensity))
+			if h&(1<<32) != 0 {
+				delta = -delta
+			}
+			img.Pix[channel] = clamp(int(img.Pix[channel]) + delta)
+		}
+	}
+}
+
+func clamp(v int) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
+
+// Rect is a filled, axis-aligned rectangle with integer coordinates, which every
+// browser rasterizes to the exact same pixels.
+type Rect struct {
+	X, Y, W, H int
+	Color      color.RGBA
+}
+
+// Drawing is a reference drawing made of opaque fillRect calls on a transparent canvas.
+type Drawing struct {
+	Name          string
+	Width, Height int
+	Rects         []Rect
+}
+
+// ReferenceDrawings are the drawings the profile hashes are computed for.
+var ReferenceDrawings = []Drawing{
+	{
+		Name: "rects", Width: 220, Height: 30,
+		Rects: []Rect{
+			{X: 125, Y: 1, W: 62, H: 20, Color: color.RGBA{0xff, 0x66, 0x00, 0xff}},
+			{X: 2, Y: 2, W: 100, H: 26, Color: color.RGBA{0x00, 0x66, 0x99, 0xff}},
+			{X: 60, Y: 10, W: 90, H: 15, Color: color.RGBA{0x66, 0xcc, 0x00, 0xff}},
+		},
+	},
+	{
+		Name: "bands", Width: 64, Height: 64,
+		Rects: []Rect{
+			{X: 0, Y: 0, W: 64, H: 8, Color: color.RGBA{0xff, 0x00, 0x00, 0xff}},
+			{X: 0, Y: 8, W: 64, H: 8, Color: color.RGBA{0xff, 0x80, 0x00, 0xff}},
+			{X: 0, Y: 16, W: 64, H: 8, Color: color.RGBA{0xff, 0xff, 0x00, 0xff}},
+			{X: 0, Y: 24, W: 64, H: 8, Color: color.RGBA{0x00, 0xff, 0x00, 0xff}},
+			{X: 0, Y: 32, W: 64, H: 8, Color: color.RGBA{0x00, 0xff, 0xff, 0xff}},
+			{X: 0, Y: 40, W: 64, H: 8, Color: color.RGBA{0x00, 0x00, 0xff, 0xff}},
+			{X: 0, Y: 48, W: 64, H: 8, Color: color.RGBA{0x80, 0x00, 0xff, 0xff}},
+			{X: 0, Y: 56, W: 64, H: 8, Color: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		},
+	},
+	{
+		Name: "checker", Width: 32, Height: 32,
+		Rects: checker(32, 4),
+	},
+}
+
+func checker(size, cell int) []Rect {
+	var rects []Rect
+	for y := 0; y < size; y += cell {
+		for x := 0; x < size; x += cell {
+			c := color.RGBA{0x00, 0x00, 0x00, 0xff}
+			if (x/cell+y/cell)%2 == 1 {
+				c = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			rects = append(rects, Rect{X: x, Y: y, W: cell, H: cell, Color: c})
+		}
+	}
+	return rects
+}
+
+// Render draws the rectangles in order on a transparent image.
+func (d Drawing) Render() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, d.Width, d.Height))
+	for _, r := range d.Rects {
+		rect := image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H).Intersect(img.Bounds())
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				img.SetRGBA(x, y, r.Color)
+			}
+		}
+	}
+	return img
+}
+
+// PixelHash returns the hex encoded SHA-256 of the RGBA pixels of the drawing with the noise
+// applied, as getImageData returns them. Pixels are hashed rather than the toDataURL output
+// because the PNG bytes depend on the encoder settings of each browser and cannot be reproduced
+// here; compare against the hash of the getImageData data of the drawn canvas.
+func (d Drawing) PixelHash(n Noise) string {
+	img := d.Render()
+	n.Apply(img)
+	sum := sha256.Sum256(img.Pix)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package canvas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoiseApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		noise Noise
+	}{
+		{name: "low", noise: Noise{Seed: 1, Intensity: 1, Density: 0.01}},
+		{name: "high", noise: Noise{Seed: 2, Intensity: 3, Density: 0.5}},
+		{name: "disabled", noise: Noise{Seed: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drawing := Drawing{Name: "half", Width: 100, Height: 100, Rects: []Rect{
+				{X: 0, Y: 0, W: 50, H: 100, Color: ReferenceDrawings[0].Rects[0].Color},
+			}}
+			original := drawing.Render()
+			img := drawing.Render()
+			tt.noise.Apply(img)
+
+			changed := 0
+			for i := 0; i < len(img.Pix); i += 4 {
+				var diff int
+				for c := 0; c < 4; c++ {
+					d := int(img.Pix[i+c]) - int(original.Pix[i+c])
+					if d != 0 {
+						require.Less(t, c, 3, "alpha must not change")
+						require.Zero(t, diff, "only one channel changes per pixel")
+						diff = d
+					}
+				}
+				if diff != 0 {
+					changed++
+					require.NotZero(t, original.Pix[i+3], "transparent pixels must not change")
+					require.LessOrEqual(t, max(diff, -diff), tt.noise.Intensity)
+				}
+			}
+
+			expected := tt.noise.Density * 50 * 100
+			require.InDelta(t, expected, changed, expected/2+5)
+		})
+	}
+}
+
+func TestDrawingPixelHash(t *testing.T) {
+	noise := Noise{Seed: 42, Intensity: 2, Density: 0.05}
+	for _, drawing := range ReferenceDrawings {
+		t.Run(drawing.Name, func(t *testing.T) {
+			img := drawing.Render()
+			noise.Apply(img)
+			sum := sha256.Sum256(img.Pix)
+			require.Equal(t, hex.EncodeToString(sum[:]), drawing.PixelHash(noise))
+			require.NotEqual(t, drawing.PixelHash(Noise{}), drawing.PixelHash(noise))
+		})
+	}
+}
+
+func TestSplitmix64(t *testing.T) {
+	// Reference outputs of SplitMix64 seeded with 0.
+	require.Equal(t, uint64(0xe220a8397b1dcdaf), splitmix64(0))
+	require.Equal(t, uint64(0x6e789e6aa1b965f4), splitmix64(0x9e3779b97f4a7c15))
+}
@@ -0,0 +1,406 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chinese-room-solutions/fakebro/cookiejar"
+	"github.com/chinese-room-solutions/fakebro/fetch"
+	"github.com/chinese-room-solutions/fakebro/profile"
+	utls "github.com/refraction-networking/utls"
+)
+
+var (
+	ErrUnsupportedScheme    = fmt.Errorf("unsupported scheme")
+	ErrUnsupportedExtension = fmt.Errorf("unsupported TLS extension")
+	ErrIncompleteProfile    = fmt.Errorf("profile has no TLS or HTTP/2 description")
+)
+
+// errConnUnusable is returned by connections that cannot take new requests,
+// e.g. after a GOAWAY. The request is retried on another connection.
+var errConnUnusable = errors.New("connection cannot take new requests")
+
+// defaultHeaders are the headers of the profile sent with every request. Browsers only send
+// the high entropy client hints to sites that ask for them, and client hints to secure origins.
+var defaultHeaders = []string{"sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform", "user-agent", "accept-language"}
+
+type options struct {
+	DialContext        func(ctx context.Context, network, addr string) (net.Conn, error)
+	RootCAs            *x509.CertPool
+	InsecureSkipVerify bool
+	CookieJar          *cookiejar.Jar
+}
+
+type Option func(*options)
+
+// WithDialContext sets the function dialing the TCP connections, e.g. through a proxy.
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(o *options) {
+		o.DialContext = dial
+	}
+}
+
+// WithRootCAs sets the certificate authorities trusted for servers instead of the system ones.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *options) {
+		o.RootCAs = pool
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of server certificates.
+func WithInsecureSkipVerify() Option {
+	return func(o *options) {
+		o.InsecureSkipVerify = true
+	}
+}
+
+// WithCookieJar makes the transport send and store cookies with the jar. Unlike http.Client.Jar,
+// the jar follows the fetch context of requests, e.g. for SameSite and third-party cookies.
+func WithCookieJar(jar *cookiejar.Jar) Option {
+	return func(o *options) {
+		o.CookieJar = jar
+	}
+}
+
+// Transport is an http.RoundTripper that sends requests like the browser of a profile:
+// the TLS ClientHello, the HTTP/2 connection preface, the header order and the default headers
+// match the profile. HTTPS origins negotiate HTTP/2 or HTTP/1.1 with ALPN, plain HTTP origins
+// use HTTP/1.1.
+//
+// The Accept, Sec-Fetch and Priority headers follow the fetch.Request of the request context,
+// see fetch.NewContext. Requests without one are sent like navigations the user typed.
+type Transport struct {
+	profile *profile.Profile
+	options options
+
+	mu    sync.Mutex
+	h2    map[string]*h2Conn
+	idle  map[string][]*h1Conn
+	dials map[string]*dialCall
+}
+
+// dialCall is a connection being dialed, that requests to the same origin wait for
+// in case it speaks HTTP/2.
+type dialCall struct {
+	done chan struct{}
+}
+
+// NewTransport returns a transport sending requests like the browser of the profile.
+func NewTransport(p *profile.Profile, opts ...Option) (*Transport, error) {
+	if p.TLS == nil || p.HTTP2 == nil {
+		return nil, ErrIncompleteProfile
+	}
+	o := options{DialContext: (&net.Dialer{}).DialContext}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Check once that utls can send the ClientHello of the profile.
+	if _, err := helloSpec(p.TLS); err != nil {
+		return nil, err
+	}
+
+	return &Transport{
+		profile: p,
+		options: o,
+		h2:      map[string]*h2Conn{},
+		idle:    map[string][]*h1Conn{},
+		dials:   map[string]*dialCall{},
+	}, nil
+}
+
+// NewClient returns an http.Client sending requests like the browser of the profile.
+func NewClient(p *profile.Profile, opts ...Option) (*http.Client, error) {
+	t, err := NewTransport(p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL == nil || req.URL.Host == "" {
+		closeBody(req)
+		return nil, errors.New("request has no URL host")
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		closeBody(req)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedScheme, req.URL.Scheme)
+	}
+
+	r := t.fetchRequest(req)
+	header, err := t.header(req, r)
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	ctx := fetch.NewContext(req.Context(), r)
+	jar := t.options.CookieJar
+	if jar != nil && req.Header.Get("Cookie") == "" {
+		var cookies []string
+		for _, c := range jar.CookiesContext(ctx, req.URL) {
+			cookies = append(cookies, c.String())
+		}
+		if len(cookies) > 0 {
+			header.Set("Cookie", strings.Join(cookies, "; "))
+		}
+	}
+	key := req.URL.Scheme + "://" + hostPort(req.URL.Scheme, req.URL.Host)
+	for {
+		cc, c, err := t.conn(req.Context(), req.URL.Scheme, key)
+		if err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		var resp *http.Response
+		if c != nil {
+			// Browsers only send the Priority header over HTTP/2 and HTTP/3.
+			if req.Header.Get("Priority") == "" {
+				header.Del("Priority")
+			}
+			resp, err = t.roundTrip1(key, c, req, header)
+			if errors.Is(err, errServerClosedIdle) && replayable(req) {
+				if req, err = rewindBody(req); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		} else {
+			resp, err = cc.roundTrip(req, header)
+			if errors.Is(err, errConnUnusable) {
+				continue
+			}
+		}
+		if err == nil && jar != nil {
+			jar.SetCookiesContext(ctx, req.URL, resp.Cookies())
+		}
+		// Like net/http, only decode the responses to the encodings the transport asked for.
+		if err == nil && req.Header.Get("Accept-Encoding") == "" && req.Method != http.MethodHead {
+			decodeResponse(resp)
+		}
+		return resp, err
+	}
+}
+
+// CloseIdleConnections closes the connections that carry no request.
+func (t *Transport) CloseIdleConnections() {
+	t.mu.Lock()
+	idle := t.idle
+	t.idle = map[string][]*h1Conn{}
+	var h2 []*h2Conn
+	for key, cc := range t.h2 {
+		if cc.idle() {
+			h2 = append(h2, cc)
+			delete(t.h2, key)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, conns := range idle {
+		for _, c := range conns {
+			c.idle.Stop()
+			c.conn.Close()
+		}
+	}
+	for _, cc := range h2 {
+		cc.close()
+	}
+}
+
+// fetchRequest returns the fetch context of the request.
+func (t *Transport) fetchRequest(req *http.Request) fetch.Request {
+	r, ok := fetch.FromContext(req.Context())
+	if !ok {
+		r = fetch.Request{Destination: fetch.DestinationDocument, UserActivated: true}
+	}
+	r.URL, r.Method = req.URL.String(), req.Method
+	return r
+}
+
+// header returns the headers of the request completed with the default headers of the profile
+// and the headers of its fetch context r.
+func (t *Transport) header(req *http.Request, r fetch.Request) (http.Header, error) {
+	browser, browserVersion := t.profile.Engine()
+	headers, err := fetch.Headers(browser, browserVersion, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range defaultHeaders {
+		if strings.HasPrefix(name, "sec-ch-") && !fetch.Secure(req.URL) {
+			continue
+		}
+		if value := t.profile.Headers[name]; value != "" {
+			headers[name] = value
+		}
+	}
+
+	header := req.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for name, value := range headers {
+		if header.Get(name) == "" {
+			header.Set(name, value)
+		}
+	}
+	return header, nil
+}
+
+// conn returns an HTTP/2 connection to the origin, or an HTTP/1.1 connection reserved for the request.
+func (t *Transport) conn(ctx context.Context, scheme, key string) (*h2Conn, *h1Conn, error) {
+	for {
+		t.mu.Lock()
+		if cc := t.h2[key]; cc != nil {
+			if cc.canTakeRequest() {
+				t.mu.Unlock()
+				return cc, nil, nil
+			}
+			delete(t.h2, key)
+		}
+		if idle := t.idle[key]; len(idle) > 0 {
+			c := idle[len(idle)-1]
+			t.idle[key] = idle[:len(idle)-1]
+			c.idle.Stop()
+			t.mu.Unlock()
+			return nil, c, nil
+		}
+		if call := t.dials[key]; call != nil {
+			t.mu.Unlock()
+			select {
+			case <-call.done:
+				continue
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			}
+		}
+		call := &dialCall{done: make(chan struct{})}
+		t.dials[key] = call
+		t.mu.Unlock()
+
+		var (
+			cc *h2Conn
+			c  *h1Conn
+		)
+		conn, h2, err := t.dial(ctx, scheme, strings.TrimPrefix(key, scheme+"://"))
+		switch {
+		case err != nil:
+		case h2:
+			cc, err = newH2Conn(conn, t.profile.HTTP2, func(cc *h2Conn) { t.removeH2(key, cc) })
+		default:
+			c = newH1Conn(conn)
+		}
+
+		t.mu.Lock()
+		delete(t.dials, key)
+		close(call.done)
+		if cc != nil {
+			t.h2[key] = cc
+		}
+		t.mu.Unlock()
+		return cc, c, err
+	}
+}
+
+// dial connects to the address and reports whether the server negotiated HTTP/2.
+func (t *Transport) dial(ctx context.Context, scheme, addr string) (net.Conn, bool, error) {
+	conn, err := t.options.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, false, err
+	}
+	if scheme == "http" {
+		return conn, false, nil
+	}
+
+	spec, err := helloSpec(t.profile.TLS)
+	if err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	// Browsers that shuffle draw a new extension order for every ClientHello.
+	if t.profile.TLS.ShuffleExtensions {
+		spec.Extensions = utls.ShuffleChromeTLSExtensions(spec.Extensions)
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	uconn := utls.UClient(conn, &utls.Config{
+		ServerName:         host,
+		RootCAs:            t.options.RootCAs,
+		InsecureSkipVerify: t.options.InsecureSkipVerify,
+	}, utls.HelloCustom)
+	if err := uconn.ApplyPreset(spec); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to apply ClientHello: %w", err)
+	}
+	if err := uconn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+	}
+	return uconn, uconn.ConnectionState().NegotiatedProtocol == "h2", nil
+}
+
+func (t *Transport) putIdle(key string, c *h1Conn) {
+	c.reused = true
+	t.mu.Lock()
+	c.idle = time.AfterFunc(idleConnTimeout, func() { t.closeIdle(key, c) })
+	t.idle[key] = append(t.idle[key], c)
+	t.mu.Unlock()
+}
+
+// closeIdle closes the connection if it is still idle.
+func (t *Transport) closeIdle(key string, c *h1Conn) {
+	t.mu.Lock()
+	i := slices.Index(t.idle[key], c)
+	if i >= 0 {
+		t.idle[key] = slices.Delete(t.idle[key], i, i+1)
+	}
+	t.mu.Unlock()
+	if i >= 0 {
+		c.conn.Close()
+	}
+}
+
+func (t *Transport) removeH2(key string, cc *h2Conn) {
+	t.mu.Lock()
+	if t.h2[key] == cc {
+		delete(t.h2, key)
+	}
+	t.mu.Unlock()
+}
+
+// hostPort returns the host with the default port of the scheme if it has none.
+func hostPort(scheme, host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if scheme == "http" {
+		return net.JoinHostPort(host, "80")
+	}
+	return net.JoinHostPort(host, "443")
+}
+
+// authority returns the host of the request without the default port of the scheme.
+func authority(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	if req.URL.Scheme == "https" {
+		return strings.TrimSuffix(host, ":443")
+	}
+	return strings.TrimSuffix(host, ":80")
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/chinese-room-solutions/fakebro/cookiejar"
+	"github.com/chinese-room-solutions/fakebro/fetch"
+	"github.com/chinese-room-solutions/fakebro/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedProfiles(t *testing.T) {
+	srv, _ := newServer(t, []string{"h2", "http/1.1"}, http.HandlerFunc(echoHandler))
+
+	for seed := int64(0); seed < 8; seed++ {
+		p, err := profile.Generate(seed)
+		require.NoError(t, err)
+		c, err := NewClient(p, WithRootCAs(rootCAs(srv)))
+		require.NoError(t, err)
+
+		resp, err := c.Get(srv.URL)
+		require.NoError(t, err, "seed %d: %s %s", seed, p.Browser, p.BrowserVersion)
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, "HTTP/2.0", resp.Proto)
+		require.Equal(t, p.Headers["user-agent"], resp.Header.Get("X-User-Agent"))
+		require.Equal(t, p.Locale.AcceptLanguage, resp.Header.Get("X-Accept-Language"))
+		c.CloseIdleConnections()
+	}
+}
+
+func TestHeader(t *testing.T) {
+	p := &profile.Profile{
+		Browser:        "Chrome",
+		BrowserVersion: "129.0",
+		Headers: map[string]string{
+			"user-agent":         "Mozilla/5.0",
+			"sec-ch-ua-mobile":   "?0",
+			"sec-ch-ua-platform": `"Linux"`,
+			"sec-ch-ua-arch":     `"x86"`,
+			"sec-ch-ua-model":    "",
+			"accept-language":    "fr-FR,fr;q=0.9",
+		},
+	}
+	tr := &Transport{profile: p}
+
+	tests := []struct {
+		name     string
+		url      string
+		fetch    *fetch.Request
+		expected http.Header
+	}{
+		{
+			name: "navigation",
+			url:  "https://example.com/",
+			expected: http.Header{
+				"User-Agent":                {"Mozilla/5.0"},
+				"Sec-Ch-Ua-Mobile":          {"?0"},
+				"Sec-Ch-Ua-Platform":        {`"Linux"`},
+				"Accept-Language":           {"en"},
+				"Accept":                    {"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+				"Accept-Encoding":           {"gzip, deflate, br, zstd"},
+				"Sec-Fetch-Site":            {"none"},
+				"Sec-Fetch-Mode":            {"navigate"},
+				"Sec-Fetch-User":            {"?1"},
+				"Sec-Fetch-Dest":            {"document"},
+				"Upgrade-Insecure-Requests": {"1"},
+				"Priority":                  {"u=0, i"},
+			},
+		},
+		{
+			name:  "insecure image",
+			url:   "http://cdn.example.com/a.png",
+			fetch: &fetch.Request{Destination: fetch.DestinationImage, Initiator: "http://example.com"},
+			expected: http.Header{
+				"User-Agent":      {"Mozilla/5.0"},
+				"Accept-Language": {"en"},
+				"Accept":          {"image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8"},
+				"Accept-Encoding": {"gzip, deflate"},
+				"Priority":        {"i"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.fetch != nil {
+				ctx = fetch.NewContext(ctx, *test.fetch)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, test.url, nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept-Language", "en")
+
+			header, err := tr.header(req, tr.fetchRequest(req))
+			require.NoError(t, err)
+			require.Equal(t, test.expected, header)
+			require.Equal(t, http.Header{"Accept-Language": {"en"}}, req.Header)
+		})
+	}
+}
+
+func TestCookieJar(t *testing.T) {
+	srv, _ := newServer(t, []string{"h2"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/", Secure: true})
+		}
+		w.Header().Set("X-Cookie", r.Header.Get("Cookie"))
+	}))
+	p := browserProfile(t, 1, "Chrome", "131.0")
+	policy, err := cookiejar.GeneratePolicy(p.Browser, p.BrowserVersion)
+	require.NoError(t, err)
+	c, err := NewClient(p, WithRootCAs(rootCAs(srv)), WithCookieJar(cookiejar.New(policy)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		fetch    *fetch.Request
+		expected string
+	}{
+		{path: "/login"},
+		{path: "/", expected: "session=1"},
+		// Chrome treats cookies without SameSite as Lax, which cross-site subresources do not get.
+		{path: "/a.png", fetch: &fetch.Request{Destination: fetch.DestinationImage, Initiator: "https://other.com"}},
+		{path: "/", fetch: &fetch.Request{Initiator: "https://other.com"}, expected: "session=1"},
+	}
+
+	for _, test := range tests {
+		ctx := context.Background()
+		if test.fetch != nil {
+			ctx = fetch.NewContext(ctx, *test.fetch)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+test.path, nil)
+		require.NoError(t, err)
+		resp, err := c.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, test.expected, resp.Header.Get("X-Cookie"), test.path)
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		url               string
+		expectedAddr      string
+		expectedAuthority string
+	}{
+		{url: "https://example.com/", expectedAddr: "example.com:443", expectedAuthority: "example.com"},
+		{url: "https://example.com:443/", expectedAddr: "example.com:443", expectedAuthority: "example.com"},
+		{url: "https://example.com:8443/", expectedAddr: "example.com:8443", expectedAuthority: "example.com:8443"},
+		{url: "http://example.com/", expectedAddr: "example.com:80", expectedAuthority: "example.com"},
+		{url: "http://[::1]/", expectedAddr: "[::1]:80", expectedAuthority: "[::1]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedAddr, hostPort(u.Scheme, u.Host))
+			require.Equal(t, test.expectedAuthority, authority(&http.Request{URL: u, Host: u.Host}))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// decoders create the readers of the content codings browsers advertise.
+var decoders = map[string]func(io.Reader) (io.Reader, error){
+	"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"deflate": func(r io.Reader) (io.Reader, error) {
+		// deflate is zlib wrapped, but some servers send raw deflate data like browsers accept.
+		br := bufio.NewReader(r)
+		header, err := br.Peek(2)
+		if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	},
+	"br": func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	"zstd": func(r io.Reader) (io.Reader, error) {
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// decodeResponse replaces the body of the response with its decoded content, like browsers do.
+// Responses with a content coding the transport does not know are left as they are.
+func decodeResponse(resp *http.Response) {
+	var encodings []string
+	for _, values := range resp.Header.Values("Content-Encoding") {
+		for _, e := range strings.Split(values, ",") {
+			e = strings.ToLower(strings.TrimSpace(e))
+			if e == "" || e == "identity" {
+				continue
+			}
+			if e == "x-gzip" {
+				e = "gzip"
+			}
+			if _, ok := decoders[e]; !ok {
+				return
+			}
+			encodings = append(encodings, e)
+		}
+	}
+	if len(encodings) == 0 || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	resp.Body = &decodedBody{body: resp.Body, encodings: encodings}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody decodes the body on the first read, so that empty bodies of e.g. HEAD or 304
+// responses do not fail.
+type decodedBody struct {
+	body      io.ReadCloser
+	encodings []string
+	r         io.Reader
+	closers   []io.Closer
+	err       error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r = b.body
+		// Codings are listed in the order they were applied.
+		for i := len(b.encodings) - 1; i >= 0 && b.err == nil; i-- {
+			var r io.Reader
+			r, b.err = decoders[b.encodings[i]](b.r)
+			if b.err != nil {
+				b.err = fmt.Errorf("failed to decode %s body: %w", b.encodings[i], b.err)
+				break
+			}
+			if c, ok := r.(io.Closer); ok {
+				b.closers = append(b.closers, c)
+			}
+			b.r = r
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	for _, c := range b.closers {
+		c.Close()
+	}
+	return b.body.Close()
+}
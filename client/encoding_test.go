@@ -0,0 +1,103 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+// encode returns the content encoded with the codings in order.
+func encode(t *testing.T, content []byte, encodings ...string) []byte {
+	for _, e := range encodings {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		var err error
+		switch e {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			w, err = flate.NewWriter(&buf, flate.DefaultCompression)
+		case "br":
+			w = brotli.NewWriter(&buf)
+		case "zstd":
+			w, err = zstd.NewWriter(&buf)
+		}
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		content = buf.Bytes()
+	}
+	return content
+}
+
+func TestDecodeResponse(t *testing.T) {
+	content := bytes.Repeat([]byte("fakebro "), 1000)
+	tests := []struct {
+		name            string
+		contentEncoding string
+		body            []byte
+		acceptEncoding  string
+		expected        []byte
+		expectedHeader  string
+	}{
+		{name: "gzip", contentEncoding: "gzip", body: encode(t, content, "gzip"), expected: content},
+		{name: "deflate", contentEncoding: "deflate", body: encode(t, content, "deflate"), expected: content},
+		{name: "raw deflate", contentEncoding: "deflate", body: encode(t, content, "raw-deflate"), expected: content},
+		{name: "br", contentEncoding: "br", body: encode(t, content, "br"), expected: content},
+		{name: "zstd", contentEncoding: "zstd", body: encode(t, content, "zstd"), expected: content},
+		{name: "several", contentEncoding: "gzip, br", body: encode(t, content, "gzip", "br"), expected: content},
+		{name: "identity", body: content, expected: content},
+		{
+			name:            "unknown",
+			contentEncoding: "compress",
+			body:            []byte("compressed"),
+			expected:        []byte("compressed"),
+			expectedHeader:  "compress",
+		},
+		{
+			name:            "requested",
+			contentEncoding: "gzip",
+			body:            encode(t, content, "gzip"),
+			acceptEncoding:  "gzip",
+			expected:        encode(t, content, "gzip"),
+			expectedHeader:  "gzip",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, _ := newServer(t, []string{"h2", "http/1.1"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.contentEncoding != "" {
+					w.Header().Set("Content-Encoding", test.contentEncoding)
+				}
+				_, _ = w.Write(test.body)
+			}))
+			c, err := NewClient(browserProfile(t, 1, "Chrome", "131.0"), WithRootCAs(rootCAs(srv)))
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			if test.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, test.expected, body)
+			require.Equal(t, test.expectedHeader, resp.Header.Get("Content-Encoding"))
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idleConnTimeout is how long HTTP/1.1 connections stay open without a request.
+const idleConnTimeout = 90 * time.Second
+
+// errServerClosedIdle is returned when a reused connection ends before the response. Servers
+// close the keep-alive connections that stayed idle for a while, so the request may be sent
+// again on a new connection.
+var errServerClosedIdle = errors.New("server closed idle connection")
+
+// h1Conn is an HTTP/1.1 connection, carrying one request at a time.
+type h1Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	bw   *bufio.Writer
+
+	// reused tells whether the connection carried a request before.
+	reused bool
+	// idle closes the connection when it stays idle for idleConnTimeout.
+	idle *time.Timer
+}
+
+func newH1Conn(conn net.Conn) *h1Conn {
+	return &h1Conn{conn: conn, br: bufio.NewReader(conn), bw: bufio.NewWriter(conn)}
+}
+
+// roundTrip1 sends the request on the connection. The connection goes back to the idle
+// connections of the origin once the response body has been read.
+func (t *Transport) roundTrip1(key string, c *h1Conn, req *http.Request, header http.Header) (*http.Response, error) {
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() { once.Do(func() { close(done) }) }
+	go func() {
+		select {
+		case <-req.Context().Done():
+			c.conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err := t.writeRequest1(c, req, header); err != nil {
+		finish()
+		c.conn.Close()
+		return nil, c.failed(req, err)
+	}
+	if _, err := c.br.Peek(1); err != nil {
+		finish()
+		c.conn.Close()
+		return nil, c.failed(req, err)
+	}
+
+	resp, err := http.ReadResponse(c.br, req)
+	if err != nil {
+		finish()
+		c.conn.Close()
+		return nil, contextError(req, err)
+	}
+
+	release := func() {
+		finish()
+		if resp.Close || req.Close {
+			c.conn.Close()
+		} else {
+			t.putIdle(key, c)
+		}
+	}
+	if resp.Body == http.NoBody {
+		release()
+		return resp, nil
+	}
+	resp.Body = &h1Body{
+		ReadCloser: resp.Body,
+		eof:        release,
+		close: func() {
+			finish()
+			c.conn.Close()
+		},
+	}
+	return resp, nil
+}
+
+// failed returns the error of a request that got no byte of response on the connection,
+// errServerClosedIdle if the connection was reused.
+func (c *h1Conn) failed(req *http.Request, err error) error {
+	err = contextError(req, err)
+	if c.reused && req.Context().Err() == nil {
+		return fmt.Errorf("%w: %w", errServerClosedIdle, err)
+	}
+	return err
+}
+
+// replayable reports whether the request may be sent again after its connection failed.
+// Like net/http, only idempotent requests whose body can be read again are.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	_, xok := req.Header["X-Idempotency-Key"]
+	return ok || xok
+}
+
+// rewindBody returns a copy of the request with a new body, to send it again.
+func rewindBody(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.Body = body
+	return &r, nil
+}
+
+// writeRequest1 writes the request with the Host and Connection headers first, like browsers,
+// then the headers in the order of the profile.
+func (t *Transport) writeRequest1(c *h1Conn, req *http.Request, header http.Header) error {
+	chunked := false
+	switch {
+	case req.Body != nil && req.Body != http.NoBody && req.ContentLength <= 0:
+		chunked = true
+	case req.ContentLength > 0 || req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch:
+		header.Set("Content-Length", fmt.Sprint(max(req.ContentLength, 0)))
+	}
+
+	fmt.Fprintf(c.bw, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI())
+	fmt.Fprintf(c.bw, "Host: %s\r\n", authority(req))
+	if req.Close {
+		c.bw.WriteString("Connection: close\r\n")
+	} else {
+		c.bw.WriteString("Connection: keep-alive\r\n")
+	}
+	for _, f := range t.profile.HTTP2.HeaderFields(req.Method, "", "", "", header) {
+		if strings.HasPrefix(f.Name, ":") {
+			continue
+		}
+		fmt.Fprintf(c.bw, "%s: %s\r\n", headerName1(f.Name), f.Value)
+	}
+	if chunked {
+		c.bw.WriteString("Transfer-Encoding: chunked\r\n")
+	}
+	c.bw.WriteString("\r\n")
+
+	if req.Body != nil && req.Body != http.NoBody {
+		defer req.Body.Close()
+		if !chunked {
+			if _, err := io.Copy(c.bw, req.Body); err != nil {
+				return err
+			}
+			return c.bw.Flush()
+		}
+		cw := httputil.NewChunkedWriter(c.bw)
+		if _, err := io.Copy(cw, req.Body); err != nil {
+			return err
+		}
+		if err := cw.Close(); err != nil {
+			return err
+		}
+		c.bw.WriteString("\r\n")
+	}
+	return c.bw.Flush()
+}
+
+// headerName1 returns the HTTP/1.1 name of the header as Chromium writes it: the client hints
+// in lower case and the other headers in canonical form.
+func headerName1(name string) string {
+	if strings.HasPrefix(name, "sec-ch-") {
+		return name
+	}
+	return http.CanonicalHeaderKey(name)
+}
+
+// h1Body calls eof when the body has been read to the end and close when it is closed before.
+type h1Body struct {
+	io.ReadCloser
+	eof, close func()
+	once       sync.Once
+}
+
+func (b *h1Body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.eof)
+	}
+	return n, err
+}
+
+func (b *h1Body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.close)
+	return err
+}
+
+// contextError returns the error of the request context if it was canceled.
+func contextError(req *http.Request, err error) error {
+	if ctxErr := req.Context().Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP1(t *testing.T) {
+	srv, conns := newServer(t, []string{"http/1.1"}, http.HandlerFunc(echoHandler))
+	p := browserProfile(t, 1, "Chrome", "129.0")
+	c, err := NewClient(p, WithRootCAs(rootCAs(srv)))
+	require.NoError(t, err)
+
+	for _, body := range []io.Reader{nil, strings.NewReader("hello"), io.MultiReader(strings.NewReader("chunked"))} {
+		var resp *http.Response
+		if body == nil {
+			resp, err = c.Get(srv.URL)
+		} else {
+			resp, err = c.Post(srv.URL, "text/plain", body)
+		}
+		require.NoError(t, err)
+		got, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, "HTTP/1.1", resp.Header.Get("X-Proto"))
+		require.Equal(t, "Chrome/129.0", resp.Header.Get("X-User-Agent"))
+		if body != nil {
+			require.NotEmpty(t, got)
+		}
+	}
+	require.Equal(t, int32(1), conns.Load())
+}
+
+func TestHTTP1Plain(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(echoHandler))
+	defer srv.Close()
+	c, err := NewClient(browserProfile(t, 1, "Safari", "17.4"))
+	require.NoError(t, err)
+
+	resp, err := c.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "hello", string(body))
+	require.Equal(t, "Safari/17.4", resp.Header.Get("X-User-Agent"))
+}
+
+func TestHTTP1HeaderOrder(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	lines := make(chan []string, 1)
+	go func() {
+		defer close(lines)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var request []string
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+			request = append(request, strings.TrimSuffix(line, "\r\n"))
+		}
+		lines <- request
+		_, _ = io.WriteString(conn, "HTTP/1.1 204 No Content\r\n\r\n")
+	}()
+
+	p := browserProfile(t, 1, "Chrome", "129.0")
+	p.Headers["sec-ch-ua-mobile"] = "?0"
+	p.Headers["sec-ch-ua-platform"] = `"Windows"`
+	p.Headers["sec-ch-ua-arch"] = `"x86"`
+	p.Headers["accept-language"] = "en-US,en;q=0.9"
+	c, err := NewClient(p)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+l.Addr().String()+"/path?q=1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.Equal(t, []string{
+		"GET /path?q=1 HTTP/1.1",
+		"Host: " + l.Addr().String(),
+		"Connection: keep-alive",
+		"sec-ch-ua-mobile: ?0",
+		`sec-ch-ua-platform: "Windows"`,
+		"Upgrade-Insecure-Requests: 1",
+		"User-Agent: Chrome/129.0",
+		"Accept: text/html",
+		"Sec-Fetch-Site: none",
+		"Sec-Fetch-Mode: navigate",
+		"Sec-Fetch-User: ?1",
+		"Sec-Fetch-Dest: document",
+		"Accept-Encoding: gzip, deflate",
+		"Accept-Language: en-US,en;q=0.9",
+	}, <-lines)
+}
+
+func TestHTTP1ClosedIdleConnection(t *testing.T) {
+	tests := []struct {
+		method        string
+		body          string
+		header        http.Header
+		expectedConns int32
+		expectedError error
+	}{
+		{method: http.MethodGet, expectedConns: 2},
+		{method: http.MethodPut, body: "hello", header: http.Header{"Idempotency-Key": {"1"}}, expectedConns: 2},
+		{method: http.MethodPost, body: "hello", expectedConns: 1, expectedError: errServerClosedIdle},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(echoHandler))
+			// The server closes keep-alive connections that stay idle for 50 ms.
+			srv.Config.IdleTimeout = 50 * time.Millisecond
+			conns := &atomic.Int32{}
+			srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					conns.Add(1)
+				}
+			}
+			srv.Start()
+			defer srv.Close()
+			c, err := NewClient(browserProfile(t, 1, "Chrome", "131.0"))
+			require.NoError(t, err)
+
+			resp, err := c.Get(srv.URL)
+			require.NoError(t, err)
+			_, err = io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			time.Sleep(200 * time.Millisecond)
+
+			req, err := http.NewRequest(test.method, srv.URL, strings.NewReader(test.body))
+			require.NoError(t, err)
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+			resp, err = c.Do(req)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+				require.Equal(t, test.body, string(body))
+			}
+			require.Equal(t, test.expectedConns, conns.Load())
+		})
+	}
+}
@@ -0,0 +1,620 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chinese-room-solutions/fakebro/http2"
+	xhttp2 "golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+var (
+	errBodyClosed   = errors.New("read on closed response body")
+	errStreamClosed = errors.New("stream closed")
+)
+
+// HTTP/2 defaults of RFC 9113 until the SETTINGS of the peer say otherwise.
+const (
+	defaultWindowSize = 65535
+	defaultFrameSize  = 16384
+	defaultMaxStreams = 100
+	defaultTableSize  = 4096
+	maxStreamID       = 1<<31 - 1
+)
+
+// h2Conn is an HTTP/2 connection multiplexing the requests to an origin. It opens with the
+// preface of the profile and sends the requests with its pseudo-header and header order.
+type h2Conn struct {
+	conn    net.Conn
+	profile *http2.Profile
+	onClose func(*h2Conn)
+
+	// wmu guards the writes, the HPACK encoder and the order of the new streams.
+	wmu  sync.Mutex
+	bw   *bufio.Writer
+	fr   *xhttp2.Framer
+	hbuf bytes.Buffer
+	henc *hpack.Encoder
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	streams map[uint32]*h2Stream
+	nextID  uint32
+	goAway  bool
+	err     error
+	// Settings of the peer.
+	maxFrameSize  uint32
+	maxStreams    uint32
+	initialWindow int32
+	sendWindow    int32
+	// Receive windows announced by the profile and the bytes read since the last WINDOW_UPDATE.
+	streamRecvWindow int32
+	connRecvWindow   int32
+	connUnacked      int32
+}
+
+type h2Stream struct {
+	id   uint32
+	body *pipe
+	// headers is closed once resp or err is set.
+	headers    chan struct{}
+	resp       *http.Response
+	err        error
+	done       chan struct{}
+	sendWindow int32
+	unacked    int32
+}
+
+func newH2Conn(conn net.Conn, p *http2.Profile, onClose func(*h2Conn)) (*h2Conn, error) {
+	cc := &h2Conn{
+		conn:             conn,
+		profile:          p,
+		onClose:          onClose,
+		bw:               bufio.NewWriter(conn),
+		streams:          map[uint32]*h2Stream{},
+		nextID:           1,
+		maxFrameSize:     defaultFrameSize,
+		maxStreams:       defaultMaxStreams,
+		initialWindow:    defaultWindowSize,
+		sendWindow:       defaultWindowSize,
+		streamRecvWindow: defaultWindowSize,
+		connRecvWindow:   defaultWindowSize + int32(p.WindowUpdate),
+	}
+	cc.cond = sync.NewCond(&cc.mu)
+	cc.henc = hpack.NewEncoder(&cc.hbuf)
+	cc.fr = xhttp2.NewFramer(cc.bw, conn)
+
+	tableSize, frameSize := uint32(defaultTableSize), uint32(defaultFrameSize)
+	for _, s := range p.Settings {
+		switch xhttp2.SettingID(s.ID) {
+		case xhttp2.SettingHeaderTableSize:
+			tableSize = s.Value
+		case xhttp2.SettingInitialWindowSize:
+			cc.streamRecvWindow = int32(s.Value)
+		case xhttp2.SettingMaxFrameSize:
+			frameSize = s.Value
+		case xhttp2.SettingMaxHeaderListSize:
+			cc.fr.MaxHeaderListSize = s.Value
+		}
+	}
+	cc.fr.ReadMetaHeaders = hpack.NewDecoder(tableSize, nil)
+	cc.fr.SetMaxReadFrameSize(frameSize)
+
+	// Requests go on the streams after the ones the PRIORITY frames of the preface declare.
+	for _, pr := range p.Priorities {
+		if pr.StreamID >= cc.nextID {
+			cc.nextID = (pr.StreamID | 1) + 2
+		}
+	}
+
+	if err := p.WritePreface(cc.bw); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := cc.bw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go cc.readLoop()
+	return cc, nil
+}
+
+func (cc *h2Conn) canTakeRequest() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.canTakeRequestLocked()
+}
+
+func (cc *h2Conn) canTakeRequestLocked() bool {
+	return cc.err == nil && !cc.goAway && len(cc.streams) < int(cc.maxStreams) && cc.nextID < maxStreamID
+}
+
+func (cc *h2Conn) idle() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return len(cc.streams) == 0
+}
+
+func (cc *h2Conn) close() {
+	cc.conn.Close()
+}
+
+// roundTrip sends the request on a new stream and waits for the response headers.
+func (cc *h2Conn) roundTrip(req *http.Request, header http.Header) (*http.Response, error) {
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	if req.ContentLength > 0 || !hasBody && (req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch) {
+		header.Set("Content-Length", strconv.FormatInt(max(req.ContentLength, 0), 10))
+	}
+	fields := cc.profile.HeaderFields(req.Method, authority(req), req.URL.Scheme, req.URL.RequestURI(), header)
+
+	s := &h2Stream{headers: make(chan struct{}), done: make(chan struct{})}
+	s.body = newPipe(func(n int) { cc.consumed(s, n) }, func() { cc.reset(s, errBodyClosed) })
+
+	cc.wmu.Lock()
+	cc.mu.Lock()
+	if !cc.canTakeRequestLocked() {
+		cc.mu.Unlock()
+		cc.wmu.Unlock()
+		return nil, errConnUnusable
+	}
+	s.id = cc.nextID
+	cc.nextID += 2
+	s.sendWindow = cc.initialWindow
+	cc.streams[s.id] = s
+	frameSize := int(cc.maxFrameSize)
+	cc.mu.Unlock()
+
+	cc.hbuf.Reset()
+	for _, f := range fields {
+		_ = cc.henc.WriteField(f)
+	}
+	err := cc.profile.WriteHeaders(cc.fr, s.id, cc.hbuf.Bytes(), !hasBody, frameSize)
+	if err == nil {
+		err = cc.bw.Flush()
+	}
+	cc.wmu.Unlock()
+	if err != nil {
+		cc.fail(err)
+		closeBody(req)
+		return nil, contextError(req, err)
+	}
+
+	if hasBody {
+		go cc.writeBody(s, req.Body)
+	}
+	go func() {
+		select {
+		case <-req.Context().Done():
+			cc.reset(s, req.Context().Err())
+		case <-s.done:
+		}
+	}()
+
+	<-s.headers
+	cc.mu.Lock()
+	resp, err := s.resp, s.err
+	cc.mu.Unlock()
+	if err != nil {
+		if errors.Is(err, errConnUnusable) && !hasBody {
+			return nil, errConnUnusable
+		}
+		return nil, contextError(req, err)
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+// writeBody sends the request body in DATA frames within the flow control windows of the peer.
+func (cc *h2Conn) writeBody(s *h2Stream, body io.ReadCloser) {
+	defer body.Close()
+	buf := make([]byte, defaultFrameSize)
+	for {
+		n, err := body.Read(buf)
+		for data := buf[:n]; len(data) > 0; {
+			k, werr := cc.awaitSendWindow(s, len(data))
+			if werr != nil {
+				return
+			}
+			if werr = cc.writeData(s, data[:k], false); werr != nil {
+				return
+			}
+			data = data[k:]
+		}
+		if err == io.EOF {
+			_ = cc.writeData(s, nil, true)
+			return
+		}
+		if err != nil {
+			cc.reset(s, err)
+			return
+		}
+	}
+}
+
+func (cc *h2Conn) writeData(s *h2Stream, data []byte, endStream bool) error {
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+	cc.mu.Lock()
+	_, ok := cc.streams[s.id]
+	cc.mu.Unlock()
+	if !ok {
+		return errStreamClosed
+	}
+
+	err := cc.fr.WriteData(s.id, endStream, data)
+	if err == nil {
+		err = cc.bw.Flush()
+	}
+	if err != nil {
+		cc.fail(err)
+	}
+	return err
+}
+
+// awaitSendWindow waits until the stream may send data and returns how many bytes, up to n.
+func (cc *h2Conn) awaitSendWindow(s *h2Stream, n int) (int, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	for {
+		if _, ok := cc.streams[s.id]; !ok {
+			return 0, errStreamClosed
+		}
+		if cc.sendWindow > 0 && s.sendWindow > 0 {
+			k := min(int32(n), cc.sendWindow, s.sendWindow, int32(cc.maxFrameSize))
+			cc.sendWindow -= k
+			s.sendWindow -= k
+			return int(k), nil
+		}
+		cc.cond.Wait()
+	}
+}
+
+// consumed gives back the flow control credit of n bytes read from the stream, or from the
+// connection only if s is nil. Like browsers, WINDOW_UPDATE frames are sent once half of a
+// window has been used.
+func (cc *h2Conn) consumed(s *h2Stream, n int) {
+	var connIncrement, streamIncrement uint32
+	cc.mu.Lock()
+	cc.connUnacked += int32(n)
+	if cc.connUnacked >= cc.connRecvWindow/2 {
+		connIncrement, cc.connUnacked = uint32(cc.connUnacked), 0
+	}
+	if s != nil {
+		if _, ok := cc.streams[s.id]; ok {
+			s.unacked += int32(n)
+			if s.unacked >= cc.streamRecvWindow/2 {
+				streamIncrement, s.unacked = uint32(s.unacked), 0
+			}
+		}
+	}
+	cc.mu.Unlock()
+	if connIncrement == 0 && streamIncrement == 0 {
+		return
+	}
+
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+	var err error
+	if connIncrement > 0 {
+		err = cc.fr.WriteWindowUpdate(0, connIncrement)
+	}
+	if err == nil && streamIncrement > 0 {
+		err = cc.fr.WriteWindowUpdate(s.id, streamIncrement)
+	}
+	if err == nil {
+		err = cc.bw.Flush()
+	}
+	if err != nil {
+		cc.fail(err)
+	}
+}
+
+// reset cancels the stream if it is still open.
+func (cc *h2Conn) reset(s *h2Stream, err error) {
+	cc.mu.Lock()
+	open := cc.endStreamLocked(s, err)
+	cc.mu.Unlock()
+	if !open {
+		return
+	}
+
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+	if cc.fr.WriteRSTStream(s.id, xhttp2.ErrCodeCancel) == nil {
+		_ = cc.bw.Flush()
+	}
+}
+
+// endStreamLocked removes the stream and ends its response with err, io.EOF when the response
+// is complete. It reports whether the stream was still open.
+func (cc *h2Conn) endStreamLocked(s *h2Stream, err error) bool {
+	if _, ok := cc.streams[s.id]; !ok {
+		return false
+	}
+	delete(cc.streams, s.id)
+	if s.resp == nil {
+		s.err = err
+		close(s.headers)
+	}
+	s.body.closeWithError(err)
+	close(s.done)
+	cc.cond.Broadcast()
+	if cc.goAway && len(cc.streams) == 0 {
+		cc.conn.Close()
+	}
+	return true
+}
+
+// fail closes the connection and ends its streams with err.
+func (cc *h2Conn) fail(err error) {
+	cc.mu.Lock()
+	if cc.err == nil {
+		cc.err = err
+	}
+	for _, s := range cc.streams {
+		cc.endStreamLocked(s, err)
+	}
+	cc.mu.Unlock()
+	cc.conn.Close()
+	cc.onClose(cc)
+}
+
+func (cc *h2Conn) readLoop() {
+	for {
+		f, err := cc.fr.ReadFrame()
+		var streamErr xhttp2.StreamError
+		if errors.As(err, &streamErr) {
+			cc.mu.Lock()
+			s := cc.streams[streamErr.StreamID]
+			cc.mu.Unlock()
+			if s != nil {
+				cc.reset(s, streamErr)
+			}
+			continue
+		}
+		if err == nil {
+			err = cc.handle(f)
+		}
+		if err != nil {
+			cc.fail(err)
+			return
+		}
+	}
+}
+
+func (cc *h2Conn) handle(f xhttp2.Frame) error {
+	switch f := f.(type) {
+	case *xhttp2.SettingsFrame:
+		if f.IsAck() {
+			return nil
+		}
+		return cc.handleSettings(f)
+	case *xhttp2.MetaHeadersFrame:
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		if s := cc.streams[f.StreamID]; s != nil {
+			cc.handleHeadersLocked(s, f)
+		}
+	case *xhttp2.DataFrame:
+		cc.mu.Lock()
+		s := cc.streams[f.StreamID]
+		data, padding := f.Data(), int(f.Header().Length)-len(f.Data())
+		if s == nil || !s.body.write(data) {
+			s, padding = nil, int(f.Header().Length)
+		} else if f.StreamEnded() {
+			cc.endStreamLocked(s, io.EOF)
+		}
+		cc.mu.Unlock()
+		if padding > 0 {
+			cc.consumed(s, padding)
+		}
+	case *xhttp2.WindowUpdateFrame:
+		cc.mu.Lock()
+		if f.StreamID == 0 {
+			cc.sendWindow += int32(f.Increment)
+		} else if s := cc.streams[f.StreamID]; s != nil {
+			s.sendWindow += int32(f.Increment)
+		}
+		cc.cond.Broadcast()
+		cc.mu.Unlock()
+	case *xhttp2.RSTStreamFrame:
+		cc.mu.Lock()
+		if s := cc.streams[f.StreamID]; s != nil {
+			cc.endStreamLocked(s, xhttp2.StreamError{StreamID: f.StreamID, Code: f.ErrCode})
+		}
+		cc.mu.Unlock()
+	case *xhttp2.PingFrame:
+		if f.IsAck() {
+			return nil
+		}
+		cc.wmu.Lock()
+		defer cc.wmu.Unlock()
+		if err := cc.fr.WritePing(true, f.Data); err != nil {
+			return err
+		}
+		return cc.bw.Flush()
+	case *xhttp2.GoAwayFrame:
+		cc.mu.Lock()
+		cc.goAway = true
+		for id, s := range cc.streams {
+			if id > f.LastStreamID {
+				cc.endStreamLocked(s, fmt.Errorf("%w: GOAWAY %v", errConnUnusable, f.ErrCode))
+			}
+		}
+		if len(cc.streams) == 0 {
+			cc.conn.Close()
+		}
+		cc.mu.Unlock()
+	case *xhttp2.PushPromiseFrame:
+		return xhttp2.ConnectionError(xhttp2.ErrCodeProtocol)
+	}
+	return nil
+}
+
+func (cc *h2Conn) handleSettings(f *xhttp2.SettingsFrame) error {
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+	cc.mu.Lock()
+	err := f.ForeachSetting(func(s xhttp2.Setting) error {
+		switch s.ID {
+		case xhttp2.SettingHeaderTableSize:
+			cc.henc.SetMaxDynamicTableSizeLimit(s.Val)
+		case xhttp2.SettingMaxConcurrentStreams:
+			cc.maxStreams = s.Val
+		case xhttp2.SettingInitialWindowSize:
+			delta := int32(s.Val) - cc.initialWindow
+			for _, stream := range cc.streams {
+				stream.sendWindow += delta
+			}
+			cc.initialWindow = int32(s.Val)
+		case xhttp2.SettingMaxFrameSize:
+			cc.maxFrameSize = s.Val
+		}
+		return nil
+	})
+	cc.cond.Broadcast()
+	cc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := cc.fr.WriteSettingsAck(); err != nil {
+		return err
+	}
+	return cc.bw.Flush()
+}
+
+// handleHeadersLocked handles the response headers or the trailers of the stream.
+func (cc *h2Conn) handleHeadersLocked(s *h2Stream, f *xhttp2.MetaHeadersFrame) {
+	if s.resp != nil {
+		if s.resp.Trailer == nil {
+			s.resp.Trailer = http.Header{}
+		}
+		for _, field := range f.RegularFields() {
+			s.resp.Trailer.Add(http.CanonicalHeaderKey(field.Name), field.Value)
+		}
+		if f.StreamEnded() {
+			cc.endStreamLocked(s, io.EOF)
+		}
+		return
+	}
+
+	code, err := strconv.Atoi(f.PseudoValue("status"))
+	if err != nil {
+		cc.endStreamLocked(s, fmt.Errorf("malformed response status %q", f.PseudoValue("status")))
+		return
+	}
+	// Informational responses precede the final one.
+	if code >= 100 && code < 200 {
+		return
+	}
+
+	header := http.Header{}
+	for _, field := range f.RegularFields() {
+		header.Add(http.CanonicalHeaderKey(field.Name), field.Value)
+	}
+	resp := &http.Response{
+		Status:        strconv.Itoa(code) + " " + http.StatusText(code),
+		StatusCode:    code,
+		Proto:         "HTTP/2.0",
+		ProtoMajor:    2,
+		Header:        header,
+		Body:          s.body,
+		ContentLength: -1,
+	}
+	if n, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		resp.ContentLength = n
+	}
+	for _, names := range header.Values("Trailer") {
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if resp.Trailer == nil {
+					resp.Trailer = http.Header{}
+				}
+				resp.Trailer[http.CanonicalHeaderKey(name)] = nil
+			}
+		}
+	}
+	s.resp = resp
+	close(s.headers)
+	if f.StreamEnded() {
+		cc.endStreamLocked(s, io.EOF)
+	}
+}
+
+// pipe is a response body filled by the read loop of the connection.
+type pipe struct {
+	mu      sync.Mutex
+	cond    sync.Cond
+	buf     bytes.Buffer
+	err     error
+	onRead  func(int)
+	onClose func()
+}
+
+func newPipe(onRead func(int), onClose func()) *pipe {
+	p := &pipe{onRead: onRead, onClose: onClose}
+	p.cond.L = &p.mu
+	return p
+}
+
+// write appends data to the body and reports whether it was still open.
+func (p *pipe) write(data []byte) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return false
+	}
+	p.buf.Write(data)
+	p.cond.Signal()
+	return true
+}
+
+func (p *pipe) closeWithError(err error) {
+	p.mu.Lock()
+	if p.err == nil {
+		p.err = err
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+}
+
+func (p *pipe) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	for p.buf.Len() == 0 && p.err == nil {
+		p.cond.Wait()
+	}
+	if p.buf.Len() == 0 {
+		err := p.err
+		p.mu.Unlock()
+		return 0, err
+	}
+	n, _ := p.buf.Read(b)
+	p.mu.Unlock()
+	p.onRead(n)
+	return n, nil
+}
+
+// Close discards the unread body and cancels the stream if the response is not complete.
+func (p *pipe) Close() error {
+	p.mu.Lock()
+	n := p.buf.Len()
+	p.buf.Reset()
+	p.err = errBodyClosed
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	if n > 0 {
+		p.onRead(n)
+	}
+	p.onClose()
+	return nil
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	stdtls "crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chinese-room-solutions/fakebro/http2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer starts a TLS server speaking the protocols and counting its connections.
+func newServer(t *testing.T, protos []string, handler http.Handler) (*httptest.Server, *atomic.Int32) {
+	srv := httptest.NewUnstartedServer(handler)
+	srv.EnableHTTP2 = true
+	srv.TLS = &stdtls.Config{NextProtos: protos}
+	conns := &atomic.Int32{}
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv, conns
+}
+
+func rootCAs(srv *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	return pool
+}
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Proto", r.Proto)
+	w.Header().Set("X-User-Agent", r.UserAgent())
+	w.Header().Set("X-Accept-Language", r.Header.Get("Accept-Language"))
+	w.Header().Set("Trailer", "X-Length")
+	n, _ := io.Copy(w, r.Body)
+	w.Header().Set("X-Length", strings.Repeat("1", int(n%7)))
+}
+
+func TestHTTP2(t *testing.T) {
+	srv, conns := newServer(t, []string{"h2", "http/1.1"}, http.HandlerFunc(echoHandler))
+	p := browserProfile(t, 1, "Chrome", "131.0")
+	p.Headers["accept-language"] = "de-DE,de;q=0.9"
+	c, err := NewClient(p, WithRootCAs(rootCAs(srv)))
+	require.NoError(t, err)
+
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "HTTP/2.0", resp.Proto)
+	require.Equal(t, "HTTP/2.0", resp.Header.Get("X-Proto"))
+	require.Equal(t, "Chrome/131.0", resp.Header.Get("X-User-Agent"))
+	require.Equal(t, "de-DE,de;q=0.9", resp.Header.Get("X-Accept-Language"))
+	require.Empty(t, body)
+
+	// A body larger than the flow control windows of the server.
+	large := bytes.Repeat([]byte("fakebro"), 300000)
+	resp, err = c.Post(srv.URL, "application/octet-stream", bytes.NewReader(large))
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, large, body)
+	require.Equal(t, strings.Repeat("1", len(large)%7), resp.Trailer.Get("X-Length"))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, "hello", string(body))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), conns.Load())
+}
+
+func TestHTTP2Cancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv, _ := newServer(t, []string{"h2"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+	}))
+	c, err := NewClient(browserProfile(t, 1, "Safari", "18.0"), WithRootCAs(rootCAs(srv)))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/slow", nil)
+	require.NoError(t, err)
+	_, err = c.Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The connection outlives the canceled stream.
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHTTP2Fingerprint(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+	}{
+		{browser: "Chrome", browserVersion: "129.0"},
+		{browser: "Firefox", browserVersion: "133.0"},
+		{browser: "Safari", browserVersion: "17.4"},
+		{browser: "Safari", browserVersion: "18.0"},
+	}
+
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	srv.Close()
+	cert := srv.TLS.Certificates[0]
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			l, err := stdtls.Listen("tcp", "127.0.0.1:0", &stdtls.Config{
+				Certificates: []stdtls.Certificate{cert},
+				NextProtos:   []string{"h2"},
+			})
+			require.NoError(t, err)
+			defer l.Close()
+
+			fingerprints := make(chan string, 1)
+			go func() {
+				defer close(fingerprints)
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				fingerprint, _ := http2.Fingerprint(conn)
+				fingerprints <- fingerprint
+			}()
+
+			p := browserProfile(t, 1, test.browser, test.browserVersion)
+			c, err := NewClient(p, WithInsecureSkipVerify())
+			require.NoError(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+l.Addr().String(), nil)
+			require.NoError(t, err)
+			_, _ = c.Do(req)
+
+			require.Equal(t, p.HTTP2.Akamai(), <-fingerprints)
+		})
+	}
+}
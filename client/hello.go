@@ -0,0 +1,114 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/chinese-room-solutions/fakebro/tls"
+	utls "github.com/refraction-networking/utls"
+)
+
+// helloSpec converts the ClientHello of the profile to the spec utls sends.
+// utls draws the GREASE values of each connection, as browsers do.
+func helloSpec(s *tls.ClientHelloSpec) (*utls.ClientHelloSpec, error) {
+	spec := &utls.ClientHelloSpec{
+		CipherSuites:       slices.Clone(s.CipherSuites),
+		CompressionMethods: []uint8{0},
+	}
+
+	for _, v := range s.SupportedVersions {
+		if tls.IsGREASE(v) {
+			continue
+		}
+		if spec.TLSVersMin == 0 || v < spec.TLSVersMin {
+			spec.TLSVersMin = v
+		}
+		spec.TLSVersMax = max(spec.TLSVersMax, v)
+	}
+	if spec.TLSVersMax == 0 {
+		spec.TLSVersMin, spec.TLSVersMax = tls.VersionTLS12, tls.VersionTLS12
+	}
+
+	for _, e := range s.Extensions {
+		ext, err := extension(s, e)
+		if err != nil {
+			return nil, err
+		}
+		spec.Extensions = append(spec.Extensions, ext)
+	}
+	return spec, nil
+}
+
+func extension(s *tls.ClientHelloSpec, e uint16) (utls.TLSExtension, error) {
+	if tls.IsGREASE(e) {
+		return &utls.UtlsGREASEExtension{}, nil
+	}
+
+	switch e {
+	case tls.ExtensionServerName:
+		return &utls.SNIExtension{}, nil
+	case tls.ExtensionStatusRequest:
+		return &utls.StatusRequestExtension{}, nil
+	case tls.ExtensionSupportedGroups:
+		curves := make([]utls.CurveID, len(s.SupportedGroups))
+		for i, g := range s.SupportedGroups {
+			curves[i] = utls.CurveID(g)
+		}
+		return &utls.SupportedCurvesExtension{Curves: curves}, nil
+	case tls.ExtensionECPointFormats:
+		return &utls.SupportedPointsExtension{SupportedPoints: slices.Clone(s.ECPointFormats)}, nil
+	case tls.ExtensionSignatureAlgorithms:
+		return &utls.SignatureAlgorithmsExtension{SupportedSignatureAlgorithms: schemes(s.SignatureAlgorithms)}, nil
+	case tls.ExtensionALPN:
+		return &utls.ALPNExtension{AlpnProtocols: slices.Clone(s.ALPN)}, nil
+	case tls.ExtensionSignedCertificateTimestamp:
+		return &utls.SCTExtension{}, nil
+	case tls.ExtensionPadding:
+		return &utls.UtlsPaddingExtension{GetPaddingLen: utls.BoringPaddingStyle}, nil
+	case tls.ExtensionExtendedMasterSecret:
+		return &utls.ExtendedMasterSecretExtension{}, nil
+	case tls.ExtensionCompressCertificate:
+		algorithms := make([]utls.CertCompressionAlgo, len(s.CertCompression))
+		for i, a := range s.CertCompression {
+			algorithms[i] = utls.CertCompressionAlgo(a)
+		}
+		return &utls.UtlsCompressCertExtension{Algorithms: algorithms}, nil
+	case tls.ExtensionRecordSizeLimit:
+		return &utls.FakeRecordSizeLimitExtension{Limit: s.RecordSizeLimit}, nil
+	case tls.ExtensionDelegatedCredentials:
+		return &utls.FakeDelegatedCredentialsExtension{SupportedSignatureAlgorithms: schemes(s.DelegatedCredentials)}, nil
+	case tls.ExtensionSessionTicket:
+		return &utls.SessionTicketExtension{}, nil
+	case tls.ExtensionSupportedVersions:
+		return &utls.SupportedVersionsExtension{Versions: slices.Clone(s.SupportedVersions)}, nil
+	case tls.ExtensionPSKKeyExchangeModes:
+		return &utls.PSKKeyExchangeModesExtension{Modes: slices.Clone(s.PSKModes)}, nil
+	case tls.ExtensionKeyShare:
+		shares := make([]utls.KeyShare, len(s.KeyShares))
+		for i, g := range s.KeyShares {
+			shares[i] = utls.KeyShare{Group: utls.CurveID(g)}
+			if tls.IsGREASE(g) {
+				shares[i].Data = []byte{0}
+			}
+		}
+		return &utls.KeyShareExtension{KeyShares: shares}, nil
+	case tls.ExtensionApplicationSettings:
+		return &utls.ApplicationSettingsExtension{SupportedProtocols: slices.Clone(s.ALPS)}, nil
+	case tls.ExtensionEncryptedClientHello:
+		ech := utls.BoringGREASEECH()
+		// The payload length of the spec includes the 16 bytes of the AES-GCM tag.
+		ech.CandidatePayloadLens = []uint16{uint16(s.ECHPayloadLength - 16)}
+		return ech, nil
+	case tls.ExtensionRenegotiationInfo:
+		return &utls.RenegotiationInfoExtension{Renegotiation: utls.RenegotiateOnceAsClient}, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnsupportedExtension, e)
+}
+
+func schemes(values []uint16) []utls.SignatureScheme {
+	s := make([]utls.SignatureScheme, len(values))
+	for i, v := range values {
+		s[i] = utls.SignatureScheme(v)
+	}
+	return s
+}
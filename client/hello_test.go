@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/chinese-room-solutions/fakebro/http2"
+	"github.com/chinese-room-solutions/fakebro/profile"
+	"github.com/chinese-room-solutions/fakebro/tls"
+	"github.com/stretchr/testify/require"
+)
+
+// browserProfile returns a profile with the TLS and HTTP/2 descriptions of the browser version.
+func browserProfile(t *testing.T, seed int64, browser, browserVersion string) *profile.Profile {
+	spec, err := tls.GenerateClientHello(seed, browser, browserVersion)
+	require.NoError(t, err)
+	h2, err := http2.GenerateProfile(browser, browserVersion)
+	require.NoError(t, err)
+	return &profile.Profile{
+		Headers:        map[string]string{"user-agent": browser + "/" + browserVersion},
+		Browser:        browser,
+		BrowserVersion: browserVersion,
+		TLS:            spec,
+		HTTP2:          h2,
+	}
+}
+
+// sentHello returns the ClientHello the client sends to the server name.
+func sentHello(t *testing.T, p *profile.Profile, serverName string) *tls.ClientHello {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	records := make(chan []byte, 1)
+	go func() {
+		defer close(records)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		record := make([]byte, 5+(int(header[3])<<8|int(header[4])))
+		copy(record, header)
+		if _, err := io.ReadFull(conn, record[5:]); err != nil {
+			return
+		}
+		records <- record
+	}()
+
+	c, err := NewClient(p, WithDialContext(func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, l.Addr().String())
+	}))
+	require.NoError(t, err)
+	_, err = c.Get("https://" + serverName + "/")
+	require.Error(t, err)
+
+	record, ok := <-records
+	require.True(t, ok, "no ClientHello received")
+	hello, err := tls.ParseClientHello(record)
+	require.NoError(t, err)
+	return hello
+}
+
+func TestClientHello(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+	}{
+		{browser: "Chrome", browserVersion: "120.0"},
+		{browser: "Chrome", browserVersion: "124.0"},
+		{browser: "Chrome", browserVersion: "131.0"},
+		{browser: "Firefox", browserVersion: "133.0"},
+		{browser: "Safari", browserVersion: "17.4"},
+		{browser: "Safari", browserVersion: "18.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			for seed := int64(0); seed < 3; seed++ {
+				p := browserProfile(t, seed, test.browser, test.browserVersion)
+				expected, err := p.TLS.Hello("example.com")
+				require.NoError(t, err)
+
+				hello := sentHello(t, p, "example.com")
+				require.Equal(t, expected.JA4(), hello.JA4())
+				if p.TLS.ShuffleExtensions {
+					require.ElementsMatch(t, withoutGREASE(expected.Extensions), withoutGREASE(hello.Extensions))
+				} else {
+					require.Equal(t, withoutGREASE(expected.Extensions), withoutGREASE(hello.Extensions))
+				}
+				require.Equal(t, withoutGREASE(expected.CipherSuites), withoutGREASE(hello.CipherSuites))
+				require.Equal(t, withoutGREASE(expected.KeyShares), withoutGREASE(hello.KeyShares))
+			}
+		})
+	}
+}
+
+func TestClientHelloShuffle(t *testing.T) {
+	p := browserProfile(t, 1, "Chrome", "131.0")
+	require.True(t, p.TLS.ShuffleExtensions)
+
+	// The chance that two shuffles of about 15 extensions are equal is negligible.
+	first := sentHello(t, p, "example.com")
+	second := sentHello(t, p, "example.com")
+	require.ElementsMatch(t, withoutGREASE(first.Extensions), withoutGREASE(second.Extensions))
+	require.NotEqual(t, withoutGREASE(first.Extensions), withoutGREASE(second.Extensions))
+	require.True(t, tls.IsGREASE(first.Extensions[0]))
+	require.True(t, tls.IsGREASE(second.Extensions[0]))
+}
+
+func TestClientHelloIP(t *testing.T) {
+	p := browserProfile(t, 1, "Chrome", "131.0")
+	hello := sentHello(t, p, "127.0.0.1")
+	require.Empty(t, hello.ServerName)
+	require.NotContains(t, hello.Extensions, tls.ExtensionServerName)
+}
+
+func TestNewTransportIncompleteProfile(t *testing.T) {
+	_, err := NewTransport(&profile.Profile{})
+	require.ErrorIs(t, err, ErrIncompleteProfile)
+
+	_, err = (&http.Client{Transport: &Transport{}}).Get("ftp://example.com/")
+	require.ErrorIs(t, err, ErrUnsupportedScheme)
+}
+
+func withoutGREASE(values []uint16) []uint16 {
+	var v []uint16
+	for _, value := range values {
+		if !tls.IsGREASE(value) {
+			v = append(v, value)
+		}
+	}
+	return v
+}
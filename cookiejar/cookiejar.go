@@ -0,0 +1,448 @@
+package cookiejar
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chinese-room-solutions/fakebro/fetch"
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/hashicorp/go-version"
+	"golang.org/x/net/publicsuffix"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedBrowser    = fmt.Errorf("unsupported browser")
+	ErrInvalidBrowserVersion = fmt.Errorf("invalid browser version")
+)
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Handling of third-party cookies, the values of Policy.ThirdParty.
+const (
+	ThirdPartyAllow     = "allow"
+	ThirdPartyPartition = "partition"
+	ThirdPartyBlock     = "block"
+)
+
+// maxCookieSize is the size limit of the name and value of a cookie every browser enforces.
+const maxCookieSize = 4096
+
+// Policy describes how a browser stores and sends cookies.
+type Policy struct {
+	Browser  string `yaml:"browser"`
+	Versions string `yaml:"versions"`
+	// LaxByDefault treats cookies without a SameSite attribute as SameSite=Lax.
+	LaxByDefault bool `yaml:"lax_by_default"`
+	// NoneRequiresSecure rejects SameSite=None cookies without the Secure attribute.
+	NoneRequiresSecure bool `yaml:"none_requires_secure"`
+	// ThirdParty is how the cookies of cross-site subresources are handled, e.g. ThirdPartyBlock.
+	ThirdParty string `yaml:"third_party"`
+	// Partitioned tells whether cookies with the Partitioned attribute are kept per top-level site.
+	Partitioned bool `yaml:"partitioned"`
+	// MaxAgeDays caps the lifetime of cookies, 0 for no cap.
+	MaxAgeDays int `yaml:"max_age_days"`
+	// MaxPerDomain caps the number of cookies of a registrable domain, 0 for no cap.
+	MaxPerDomain int `yaml:"max_per_domain"`
+	// MaxTotal caps the number of cookies, 0 for no cap.
+	MaxTotal int `yaml:"max_total"`
+
+	versions version.Constraints
+}
+
+type cookieData struct {
+	Policies []Policy `yaml:"policies"`
+}
+
+var data cookieData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for i := range data.Policies {
+		p := &data.Policies[i]
+		p.versions, err = version.NewConstraint(p.Versions)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid versions for %s: %v", p.Browser, err))
+		}
+		switch p.ThirdParty {
+		case ThirdPartyAllow, ThirdPartyPartition, ThirdPartyBlock:
+		default:
+			panic(fmt.Sprintf("Invalid third_party for %s %s: %s", p.Browser, p.Versions, p.ThirdParty))
+		}
+	}
+}
+
+// GeneratePolicy returns the cookie policy of the browser version, e.g. "Chrome" and "129.0".
+func GeneratePolicy(browser, browserVersion string) (*Policy, error) {
+	browser, v, err := family.Parse(browser, browserVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBrowserVersion, browserVersion)
+	}
+
+	for _, p := range data.Policies {
+		if p.Browser == browser && p.versions.Check(v) {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedBrowser, browser, browserVersion)
+}
+
+// entry is a stored cookie.
+type entry struct {
+	Name     string
+	Value    string
+	Quoted   bool
+	Domain   string
+	HostOnly bool
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+	// Expires is zero for session cookies.
+	Expires time.Time
+
+	// created and accessed order the cookies by creation and last use.
+	created  uint64
+	accessed uint64
+}
+
+func (e *entry) key() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+// Jar is an http.CookieJar storing and sending cookies like the browser of its policy:
+// SameSite defaults, third-party cookie blocking or partitioning, lifetime and count caps.
+//
+// SetCookies and Cookies treat requests as top-level navigations. SetCookiesContext and
+// CookiesContext follow the fetch.Request of the context, see fetch.NewContext.
+type Jar struct {
+	policy *Policy
+
+	mu sync.Mutex
+	// partitions holds the cookies by partition key and entry key. Unpartitioned cookies
+	// are under the empty partition key, the others under the site of the top-level document.
+	partitions map[string]map[string]*entry
+	seq        uint64
+}
+
+// New returns an empty jar following the policy.
+func New(policy *Policy) *Jar {
+	return &Jar{policy: policy, partitions: map[string]map[string]*entry{}}
+}
+
+// SetCookies implements http.CookieJar.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.SetCookiesContext(context.Background(), u, cookies)
+}
+
+// Cookies implements http.CookieJar.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.CookiesContext(context.Background(), u)
+}
+
+// requestContext is where a request is sent from.
+type requestContext struct {
+	// partition is the site of the top-level document.
+	partition string
+	// crossSite tells whether the request goes to another site than its initiator.
+	crossSite bool
+	// thirdParty tells whether a subresource goes to another site than the top-level document.
+	thirdParty bool
+	// laxAllowed tells whether cross-site requests send SameSite=Lax cookies, which browsers
+	// do for top-level navigations with safe methods.
+	laxAllowed bool
+}
+
+func newRequestContext(ctx context.Context, u *url.URL) requestContext {
+	r, ok := fetch.FromContext(ctx)
+	if !ok {
+		r = fetch.Request{Destination: fetch.DestinationDocument}
+	}
+	topLevel := r.Destination == "" || r.Destination == fetch.DestinationDocument
+	initiator, err := url.Parse(r.Initiator)
+	if r.Initiator == "" || err != nil || initiator.Host == "" {
+		return requestContext{partition: site(u), laxAllowed: true}
+	}
+
+	c := requestContext{
+		partition:  site(initiator),
+		crossSite:  fetch.Site(initiator, u) == fetch.SiteCrossSite,
+		laxAllowed: topLevel && (r.Method == "" || r.Method == http.MethodGet || r.Method == http.MethodHead),
+	}
+	if topLevel {
+		c.partition = site(u)
+	} else {
+		c.thirdParty = c.crossSite
+	}
+	return c
+}
+
+// SetCookiesContext stores the cookies of a response to a request sent in the fetch context of ctx.
+// Cookies the browser would reject are ignored.
+func (j *Jar) SetCookiesContext(ctx context.Context, u *url.URL, cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return
+	}
+	rc := newRequestContext(ctx, u)
+	if rc.thirdParty && j.policy.ThirdParty == ThirdPartyBlock && !j.policy.Partitioned {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		j.setCookie(u, rc, c, now)
+	}
+	j.evict(now)
+}
+
+func (j *Jar) setCookie(u *url.URL, rc requestContext, c *http.Cookie, now time.Time) {
+	if c.Name == "" || len(c.Name)+len(c.Value) > maxCookieSize {
+		return
+	}
+	host := canonicalHost(u)
+	secureOrigin := fetch.Secure(u)
+	e := &entry{Name: c.Name, Value: c.Value, Quoted: c.Quoted, Secure: c.Secure, SameSite: c.SameSite}
+
+	if e.Secure && !secureOrigin {
+		return
+	}
+	e.Domain, e.HostOnly = host, true
+	if domain := strings.TrimPrefix(strings.ToLower(c.Domain), "."); domain != "" {
+		ps, _ := publicsuffix.PublicSuffix(domain)
+		switch {
+		case domain == host && (net.ParseIP(host) != nil || ps == domain):
+			// IP addresses and public suffixes only get host-only cookies.
+		case domain == host:
+			e.HostOnly = false
+		case net.ParseIP(host) != nil || ps == domain || !strings.HasSuffix(host, "."+domain):
+			return
+		default:
+			e.Domain, e.HostOnly = domain, false
+		}
+	}
+	e.Path = c.Path
+	if !strings.HasPrefix(e.Path, "/") {
+		e.Path = defaultPath(u.Path)
+	}
+	if strings.HasPrefix(e.Name, "__Secure-") && !e.Secure {
+		return
+	}
+	if strings.HasPrefix(e.Name, "__Host-") && (!e.Secure || !e.HostOnly || e.Path != "/") {
+		return
+	}
+
+	switch e.SameSite {
+	case http.SameSiteLaxMode, http.SameSiteStrictMode, http.SameSiteNoneMode:
+	default:
+		e.SameSite = http.SameSiteNoneMode
+		if j.policy.LaxByDefault {
+			e.SameSite = http.SameSiteLaxMode
+		}
+	}
+	if e.SameSite == http.SameSiteNoneMode && j.policy.NoneRequiresSecure && !e.Secure {
+		return
+	}
+	// Cross-site subresources cannot set SameSite cookies they would not receive.
+	if e.SameSite != http.SameSiteNoneMode && rc.crossSite && !rc.laxAllowed {
+		return
+	}
+
+	partition := ""
+	switch {
+	case c.Partitioned && j.policy.Partitioned:
+		if !e.Secure {
+			return
+		}
+		partition = rc.partition
+	case rc.thirdParty && j.policy.ThirdParty == ThirdPartyPartition:
+		partition = rc.partition
+	case rc.thirdParty && j.policy.ThirdParty == ThirdPartyBlock:
+		return
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		e.Expires = now
+	case c.MaxAge > 0:
+		e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	default:
+		e.Expires = c.Expires
+	}
+	if limit := now.AddDate(0, 0, j.policy.MaxAgeDays); j.policy.MaxAgeDays > 0 && e.Expires.After(limit) {
+		e.Expires = limit
+	}
+
+	entries := j.partitions[partition]
+	// Insecure origins cannot overwrite the secure cookies of their domain.
+	if !secureOrigin {
+		for _, old := range entries {
+			if old.Secure && old.Name == e.Name && (domainMatch(old, e.Domain) || domainMatch(e, old.Domain)) && pathMatch(old.Path, e.Path) {
+				return
+			}
+		}
+	}
+	if !e.Expires.IsZero() && !e.Expires.After(now) {
+		delete(entries, e.key())
+		return
+	}
+	if entries == nil {
+		entries = map[string]*entry{}
+		j.partitions[partition] = entries
+	}
+	j.seq++
+	e.created, e.accessed = j.seq, j.seq
+	if old, ok := entries[e.key()]; ok {
+		e.created = old.created
+	}
+	entries[e.key()] = e
+}
+
+// evict removes the expired cookies, then the least recently used ones over the caps of the policy.
+func (j *Jar) evict(now time.Time) {
+	var all []*entry
+	partitionOf := map[*entry]string{}
+	for partition, entries := range j.partitions {
+		for key, e := range entries {
+			if !e.Expires.IsZero() && !e.Expires.After(now) {
+				delete(entries, key)
+				continue
+			}
+			all = append(all, e)
+			partitionOf[e] = partition
+		}
+	}
+	sort.Slice(all, func(a, b int) bool { return all[a].accessed > all[b].accessed })
+
+	perDomain := map[string]int{}
+	for i, e := range all {
+		domain := partitionOf[e] + ";" + registrableDomain(e.Domain)
+		perDomain[domain]++
+		if (j.policy.MaxPerDomain > 0 && perDomain[domain] > j.policy.MaxPerDomain) ||
+			(j.policy.MaxTotal > 0 && i >= j.policy.MaxTotal) {
+			delete(j.partitions[partitionOf[e]], e.key())
+		}
+	}
+}
+
+// CookiesContext returns the cookies to send with a request sent in the fetch context of ctx.
+func (j *Jar) CookiesContext(ctx context.Context, u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	rc := newRequestContext(ctx, u)
+	partitions := []string{rc.partition}
+	if !rc.thirdParty || j.policy.ThirdParty == ThirdPartyAllow {
+		partitions = append(partitions, "")
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	host, secureOrigin := canonicalHost(u), fetch.Secure(u)
+	requestPath := u.Path
+	if requestPath == "" {
+		requestPath = "/"
+	}
+
+	var selected []*entry
+	for _, partition := range partitions {
+		for key, e := range j.partitions[partition] {
+			if !e.Expires.IsZero() && !e.Expires.After(now) {
+				delete(j.partitions[partition], key)
+				continue
+			}
+			if !domainMatch(e, host) || !pathMatch(requestPath, e.Path) || (e.Secure && !secureOrigin) {
+				continue
+			}
+			if rc.crossSite && (e.SameSite == http.SameSiteStrictMode || (e.SameSite == http.SameSiteLaxMode && !rc.laxAllowed)) {
+				continue
+			}
+			selected = append(selected, e)
+		}
+	}
+	// Longer paths first, then older cookies first, like RFC 6265 recommends.
+	sort.Slice(selected, func(a, b int) bool {
+		if len(selected[a].Path) != len(selected[b].Path) {
+			return len(selected[a].Path) > len(selected[b].Path)
+		}
+		return selected[a].created < selected[b].created
+	})
+
+	cookies := make([]*http.Cookie, 0, len(selected))
+	for _, e := range selected {
+		j.seq++
+		e.accessed = j.seq
+		cookies = append(cookies, &http.Cookie{Name: e.Name, Value: e.Value, Quoted: e.Quoted})
+	}
+	return cookies
+}
+
+// canonicalHost returns the lower case host of the URL without port and IPv6 brackets.
+func canonicalHost(u *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// site returns the schemeful site of the URL, the key of the partition of its documents.
+func site(u *url.URL) string {
+	return strings.ToLower(u.Scheme) + "://" + registrableDomain(canonicalHost(u))
+}
+
+// registrableDomain returns the eTLD+1 of the host, or the host itself for IP addresses and
+// hosts that are public suffixes.
+func registrableDomain(host string) string {
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// defaultPath returns the default cookie path of a request path, RFC 6265 section 5.1.4.
+func defaultPath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// domainMatch reports whether the cookie is sent to the host.
+func domainMatch(e *entry, host string) bool {
+	if e.HostOnly {
+		return host == e.Domain
+	}
+	return host == e.Domain || strings.HasSuffix(host, "."+e.Domain)
+}
+
+// pathMatch reports whether the request path is in the cookie path, RFC 6265 section 5.1.4.
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
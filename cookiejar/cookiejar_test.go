@@ -0,0 +1,319 @@
+package cookiejar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/chinese-room-solutions/fakebro/fetch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePolicy(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+		expected       *Policy
+		expectedError  error
+	}{
+		{
+			browser:        "Chrome",
+			browserVersion: "131.0.6778.86",
+			expected: &Policy{
+				Browser: "chrome", Versions: ">= 114", LaxByDefault: true, NoneRequiresSecure: true,
+				ThirdParty: ThirdPartyAllow, Partitioned: true, MaxAgeDays: 400, MaxPerDomain: 180, MaxTotal: 3300,
+			},
+		},
+		{
+			browser:        "Edge",
+			browserVersion: "90.0",
+			expected: &Policy{
+				Browser: "chrome", Versions: ">= 80", LaxByDefault: true, NoneRequiresSecure: true,
+				ThirdParty: ThirdPartyAllow, MaxPerDomain: 180, MaxTotal: 3300,
+			},
+		},
+		{
+			browser:        "Firefox",
+			browserVersion: "133.0",
+			expected:       &Policy{Browser: "firefox", Versions: ">= 103", ThirdParty: ThirdPartyPartition, MaxPerDomain: 180, MaxTotal: 3000},
+		},
+		{
+			browser:        "Safari",
+			browserVersion: "17.4",
+			expected:       &Policy{Browser: "safari", Versions: ">= 13.1", ThirdParty: ThirdPartyBlock},
+		},
+		{browser: "Safari", browserVersion: "12.0", expectedError: ErrUnsupportedBrowser},
+		{browser: "Chrome", browserVersion: "latest", expectedError: ErrInvalidBrowserVersion},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			p, err := GeneratePolicy(test.browser, test.browserVersion)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			p.versions = nil
+			require.Equal(t, test.expected, p)
+		})
+	}
+}
+
+func newJar(t *testing.T, browser, browserVersion string) *Jar {
+	p, err := GeneratePolicy(browser, browserVersion)
+	require.NoError(t, err)
+	return New(p)
+}
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u
+}
+
+// names returns the names and values of the cookies the jar sends to the URL in the request context.
+func names(t *testing.T, j *Jar, r fetch.Request, rawURL string) []string {
+	var got []string
+	for _, c := range j.CookiesContext(fetch.NewContext(context.Background(), r), mustParse(t, rawURL)) {
+		got = append(got, c.Name+"="+c.Value)
+	}
+	return got
+}
+
+func TestCookies(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		cookies  []*http.Cookie
+		request  fetch.Request
+		target   string
+		expected []string
+	}{
+		{
+			name:     "host only",
+			url:      "https://www.example.com/",
+			cookies:  []*http.Cookie{{Name: "a", Value: "1"}},
+			target:   "https://sub.www.example.com/",
+			expected: nil,
+		},
+		{
+			name:     "domain",
+			url:      "https://www.example.com/",
+			cookies:  []*http.Cookie{{Name: "a", Value: "1", Domain: ".example.com"}},
+			target:   "https://api.example.com/",
+			expected: []string{"a=1"},
+		},
+		{
+			name:    "public suffix domain",
+			url:     "https://user.github.io/",
+			cookies: []*http.Cookie{{Name: "a", Value: "1", Domain: "github.io"}},
+			target:  "https://user.github.io/",
+		},
+		{
+			name:     "paths",
+			url:      "https://example.com/docs/page",
+			cookies:  []*http.Cookie{{Name: "a", Value: "1", Path: "/"}, {Name: "b", Value: "2"}, {Name: "c", Value: "3", Path: "/other"}},
+			target:   "https://example.com/docs/next",
+			expected: []string{"b=2", "a=1"},
+		},
+		{
+			name:     "secure",
+			url:      "https://example.com/",
+			cookies:  []*http.Cookie{{Name: "a", Value: "1", Secure: true}, {Name: "b", Value: "2"}},
+			target:   "http://example.com/",
+			expected: []string{"b=2"},
+		},
+		{
+			name:    "secure from insecure origin",
+			url:     "http://example.com/",
+			cookies: []*http.Cookie{{Name: "a", Value: "1", Secure: true}},
+			target:  "https://example.com/",
+		},
+		{
+			name:     "prefixes",
+			url:      "https://www.example.com/",
+			cookies:  []*http.Cookie{{Name: "__Secure-a", Value: "1"}, {Name: "__Host-b", Value: "2", Secure: true, Domain: "example.com", Path: "/"}, {Name: "__Host-c", Value: "3", Secure: true, Path: "/"}},
+			target:   "https://www.example.com/",
+			expected: []string{"__Host-c=3"},
+		},
+		{
+			name:     "expired",
+			url:      "https://example.com/",
+			cookies:  []*http.Cookie{{Name: "a", Value: "1", MaxAge: -1}, {Name: "b", Value: "2", Expires: time.Now().Add(-time.Hour)}, {Name: "c", Value: "3", MaxAge: 60}},
+			target:   "https://example.com/",
+			expected: []string{"c=3"},
+		},
+		{
+			name:    "too large",
+			url:     "https://example.com/",
+			cookies: []*http.Cookie{{Name: "a", Value: string(make([]byte, maxCookieSize))}},
+			target:  "https://example.com/",
+		},
+		{
+			name:     "cross-site navigation",
+			url:      "https://example.com/",
+			cookies:  []*http.Cookie{{Name: "lax", Value: "1"}, {Name: "strict", Value: "2", SameSite: http.SameSiteStrictMode}},
+			request:  fetch.Request{Initiator: "https://other.com"},
+			target:   "https://example.com/",
+			expected: []string{"lax=1"},
+		},
+		{
+			name:     "cross-site POST navigation",
+			url:      "https://example.com/",
+			cookies:  []*http.Cookie{{Name: "lax", Value: "1"}, {Name: "none", Value: "2", SameSite: http.SameSiteNoneMode, Secure: true}},
+			request:  fetch.Request{Method: http.MethodPost, Initiator: "https://other.com"},
+			target:   "https://example.com/",
+			expected: []string{"none=2"},
+		},
+		{
+			name:     "same-site subresource",
+			url:      "https://example.com/",
+			cookies:  []*http.Cookie{{Name: "strict", Value: "1", SameSite: http.SameSiteStrictMode, Domain: "example.com"}},
+			request:  fetch.Request{Destination: fetch.DestinationImage, Initiator: "https://www.example.com"},
+			target:   "https://cdn.example.com/a.png",
+			expected: []string{"strict=1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			j := newJar(t, "Chrome", "131.0")
+			j.SetCookies(mustParse(t, test.url), test.cookies)
+			require.Equal(t, test.expected, names(t, j, test.request, test.target))
+		})
+	}
+}
+
+func TestSameSiteDefaults(t *testing.T) {
+	tests := []struct {
+		browser  string
+		version  string
+		expected []string
+	}{
+		{browser: "Chrome", version: "131.0", expected: []string{"none=2"}},
+		{browser: "Firefox", version: "133.0", expected: []string{"default=1", "none=2", "insecure=3"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser, func(t *testing.T) {
+			j := newJar(t, test.browser, test.version)
+			j.SetCookies(mustParse(t, "https://example.com/"), []*http.Cookie{
+				{Name: "default", Value: "1"},
+				{Name: "none", Value: "2", SameSite: http.SameSiteNoneMode, Secure: true},
+				{Name: "insecure", Value: "3", SameSite: http.SameSiteNoneMode},
+			})
+			require.ElementsMatch(t, test.expected, names(t, j, fetch.Request{Method: http.MethodPost, Initiator: "https://other.com"}, "https://example.com/"))
+		})
+	}
+}
+
+func TestThirdParty(t *testing.T) {
+	embedded := fetch.Request{Destination: fetch.DestinationIframe, Initiator: "https://site.com"}
+	other := fetch.Request{Destination: fetch.DestinationIframe, Initiator: "https://other.com"}
+	tests := []struct {
+		name    string
+		browser string
+		version string
+		cookie  *http.Cookie
+		// expected are the cookies sent to the tracker in the first-party, same top-level
+		// and other top-level contexts.
+		expected [3][]string
+	}{
+		{
+			name:     "Chrome SameSite=None",
+			browser:  "Chrome",
+			version:  "131.0",
+			cookie:   &http.Cookie{Name: "id", Value: "1", SameSite: http.SameSiteNoneMode, Secure: true},
+			expected: [3][]string{{"id=1"}, {"id=1"}, {"id=1"}},
+		},
+		{
+			name:     "Chrome partitioned",
+			browser:  "Chrome",
+			version:  "131.0",
+			cookie:   &http.Cookie{Name: "id", Value: "1", SameSite: http.SameSiteNoneMode, Secure: true, Partitioned: true},
+			expected: [3][]string{nil, {"id=1"}, nil},
+		},
+		{
+			name:     "Chrome default SameSite",
+			browser:  "Chrome",
+			version:  "131.0",
+			cookie:   &http.Cookie{Name: "id", Value: "1"},
+			expected: [3][]string{nil, nil, nil},
+		},
+		{
+			name:     "Firefox total cookie protection",
+			browser:  "Firefox",
+			version:  "133.0",
+			cookie:   &http.Cookie{Name: "id", Value: "1", Secure: true},
+			expected: [3][]string{nil, {"id=1"}, nil},
+		},
+		{
+			name:     "Firefox 100",
+			browser:  "Firefox",
+			version:  "100.0",
+			cookie:   &http.Cookie{Name: "id", Value: "1", Secure: true},
+			expected: [3][]string{{"id=1"}, {"id=1"}, {"id=1"}},
+		},
+		{
+			name:     "Safari",
+			browser:  "Safari",
+			version:  "18.2",
+			cookie:   &http.Cookie{Name: "id", Value: "1", SameSite: http.SameSiteNoneMode, Secure: true},
+			expected: [3][]string{nil, nil, nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			j := newJar(t, test.browser, test.version)
+			ctx := fetch.NewContext(context.Background(), embedded)
+			j.SetCookiesContext(ctx, mustParse(t, "https://tracker.com/"), []*http.Cookie{test.cookie})
+
+			require.Equal(t, test.expected[0], names(t, j, fetch.Request{}, "https://tracker.com/"))
+			require.Equal(t, test.expected[1], names(t, j, embedded, "https://tracker.com/"))
+			require.Equal(t, test.expected[2], names(t, j, other, "https://tracker.com/"))
+		})
+	}
+}
+
+func TestFirstPartyInThirdPartyContext(t *testing.T) {
+	j := newJar(t, "Safari", "18.2")
+	j.SetCookies(mustParse(t, "https://tracker.com/"), []*http.Cookie{{Name: "id", Value: "1", Secure: true}})
+	require.Equal(t, []string{"id=1"}, names(t, j, fetch.Request{}, "https://tracker.com/"))
+	require.Nil(t, names(t, j, fetch.Request{Destination: fetch.DestinationScript, Initiator: "https://site.com"}, "https://tracker.com/a.js"))
+}
+
+func TestMaxAge(t *testing.T) {
+	j := newJar(t, "Chrome", "131.0")
+	u := mustParse(t, "https://example.com/")
+	j.SetCookies(u, []*http.Cookie{{Name: "a", Value: "1", MaxAge: 10 * 365 * 24 * 3600}})
+	e := j.partitions[""]["example.com;/;a"]
+	require.NotNil(t, e)
+	require.WithinDuration(t, time.Now().AddDate(0, 0, 400), e.Expires, time.Minute)
+}
+
+func TestEviction(t *testing.T) {
+	j := newJar(t, "Firefox", "133.0")
+	u := mustParse(t, "https://example.com/")
+	for i := 0; i < 200; i++ {
+		j.SetCookies(u, []*http.Cookie{{Name: fmt.Sprintf("c%d", i), Value: "1"}})
+	}
+	got := names(t, j, fetch.Request{}, "https://example.com/")
+	require.Len(t, got, 180)
+	require.Contains(t, got, "c199=1")
+	require.NotContains(t, got, "c19=1")
+
+	j.SetCookies(mustParse(t, "https://other.com/"), []*http.Cookie{{Name: "a", Value: "1"}})
+	require.Equal(t, []string{"a=1"}, names(t, j, fetch.Request{}, "https://other.com/"))
+}
+
+func TestSecureOverwrite(t *testing.T) {
+	j := newJar(t, "Chrome", "131.0")
+	j.SetCookies(mustParse(t, "https://example.com/"), []*http.Cookie{{Name: "a", Value: "secure", Secure: true, SameSite: http.SameSiteLaxMode}})
+	j.SetCookies(mustParse(t, "http://example.com/"), []*http.Cookie{{Name: "a", Value: "insecure"}})
+	require.Equal(t, []string{"a=secure"}, names(t, j, fetch.Request{}, "https://example.com/"))
+}
@@ -0,0 +1,310 @@
+package fetch
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/hashicorp/go-version"
+	"golang.org/x/net/publicsuffix"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedBrowser    = fmt.Errorf("unsupported browser")
+	ErrInvalidBrowserVersion = fmt.Errorf("invalid browser version")
+	ErrUnknownDestination    = fmt.Errorf("unknown destination")
+	ErrInvalidURL            = fmt.Errorf("invalid URL")
+)
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Destinations of requests, the values of Sec-Fetch-Dest.
+const (
+	DestinationDocument = "document"
+	DestinationIframe   = "iframe"
+	DestinationImage    = "image"
+	DestinationScript   = "script"
+	DestinationStyle    = "style"
+	DestinationFont     = "font"
+	DestinationAudio    = "audio"
+	DestinationVideo    = "video"
+	DestinationManifest = "manifest"
+	DestinationWorker   = "worker"
+	// DestinationEmpty is the destination of fetch() and XMLHttpRequest.
+	DestinationEmpty = "empty"
+)
+
+// Request modes, the values of Sec-Fetch-Mode.
+const (
+	ModeNavigate   = "navigate"
+	ModeNoCORS     = "no-cors"
+	ModeCORS       = "cors"
+	ModeSameOrigin = "same-origin"
+)
+
+// Relations between the initiator and the target of a request, the values of Sec-Fetch-Site.
+const (
+	SiteNone       = "none"
+	SiteSameOrigin = "same-origin"
+	SiteSameSite   = "same-site"
+	SiteCrossSite  = "cross-site"
+)
+
+// Request is the context a browser sends a request in.
+type Request struct {
+	// URL is the requested URL.
+	URL string
+	// Method is the request method, GET if empty.
+	Method string
+	// Destination is what the response is used for, DestinationDocument if empty.
+	Destination string
+	// Mode overrides the mode of the destination, e.g. ModeCORS for module scripts or
+	// images with a crossorigin attribute.
+	Mode string
+	// Initiator is the origin of the document sending the request, e.g. "https://example.com",
+	// or empty when the user types the URL or opens a bookmark.
+	Initiator string
+	// UserActivated tells whether a navigation follows a user action like a click or typing the URL.
+	UserActivated bool
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request context, for clients that send requests
+// like browsers.
+func NewContext(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the request context ctx carries, if any.
+func FromContext(ctx context.Context) (Request, bool) {
+	r, ok := ctx.Value(contextKey{}).(Request)
+	return r, ok
+}
+
+type destination struct {
+	Accept         string `yaml:"accept"`
+	Priority       string `yaml:"priority"`
+	AcceptEncoding string `yaml:"accept_encoding"`
+}
+
+type fetchProfile struct {
+	Browser      string                 `yaml:"browser"`
+	Versions     string                 `yaml:"versions"`
+	SendPriority bool                   `yaml:"send_priority"`
+	Destinations map[string]destination `yaml:"destinations"`
+
+	versions version.Constraints
+}
+
+type encoding struct {
+	Browser  string `yaml:"browser"`
+	Versions string `yaml:"versions"`
+	Secure   string `yaml:"secure"`
+	Insecure string `yaml:"insecure"`
+
+	versions version.Constraints
+}
+
+type fetchData struct {
+	Modes     map[string]string `yaml:"modes"`
+	Profiles  []fetchProfile    `yaml:"profiles"`
+	Encodings []encoding        `yaml:"encodings"`
+}
+
+var data fetchData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for i := range data.Profiles {
+		p := &data.Profiles[i]
+		p.versions, err = version.NewConstraint(p.Versions)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid versions for %s: %v", p.Browser, err))
+		}
+		for dest := range data.Modes {
+			if p.Destinations[dest].Accept == "" {
+				panic(fmt.Sprintf("No %s destination for %s %s", dest, p.Browser, p.Versions))
+			}
+		}
+	}
+	for i := range data.Encodings {
+		e := &data.Encodings[i]
+		e.versions, err = version.NewConstraint(e.Versions)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid encoding versions for %s: %v", e.Browser, err))
+		}
+	}
+}
+
+// AcceptEncoding returns the Accept-Encoding header the browser version sends to HTTPS origins,
+// or to plain HTTP ones if secure is false.
+func AcceptEncoding(browser, browserVersion string, secure bool) (string, error) {
+	browser, v, err := family.Parse(browser, browserVersion)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidBrowserVersion, browserVersion)
+	}
+	for _, e := range data.Encodings {
+		if e.Browser == browser && e.versions.Check(v) {
+			if secure {
+				return e.Secure, nil
+			}
+			return e.Insecure, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s %s", ErrUnsupportedBrowser, browser, browserVersion)
+}
+
+// Headers returns the headers the browser version, e.g. "Chrome" and "129.0", sends for the
+// request besides the user agent and language ones: Accept, Accept-Encoding, the Sec-Fetch
+// headers, Upgrade-Insecure-Requests, Priority and Origin. Header names are lower case.
+// The Sec-Fetch headers are only sent to potentially trustworthy targets, see Secure.
+func Headers(browser, browserVersion string, r Request) (map[string]string, error) {
+	browser, v, err := family.Parse(browser, browserVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBrowserVersion, browserVersion)
+	}
+
+	var p *fetchProfile
+	for i := range data.Profiles {
+		if data.Profiles[i].Browser == browser && data.Profiles[i].versions.Check(v) {
+			p = &data.Profiles[i]
+			break
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedBrowser, browser, browserVersion)
+	}
+
+	if r.Destination == "" {
+		r.Destination = DestinationDocument
+	}
+	dest, ok := p.Destinations[r.Destination]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDestination, r.Destination)
+	}
+	mode := r.Mode
+	if mode == "" {
+		mode = data.Modes[r.Destination]
+	}
+	target, err := url.Parse(r.URL)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, r.URL)
+	}
+	site, initiator := SiteNone, ""
+	if r.Initiator != "" {
+		i, err := url.Parse(r.Initiator)
+		if err != nil || i.Host == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidURL, r.Initiator)
+		}
+		site, initiator = Site(i, target), origin(i)
+	}
+
+	acceptEncoding := dest.AcceptEncoding
+	if acceptEncoding == "" {
+		acceptEncoding, err = AcceptEncoding(browser, browserVersion, target.Scheme == "https")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h := map[string]string{
+		"accept":          dest.Accept,
+		"accept-encoding": acceptEncoding,
+	}
+	secure := Secure(target)
+	if secure {
+		h["sec-fetch-site"] = site
+		h["sec-fetch-mode"] = mode
+		h["sec-fetch-dest"] = r.Destination
+	}
+	if mode == ModeNavigate {
+		h["upgrade-insecure-requests"] = "1"
+		if secure && r.UserActivated {
+			h["sec-fetch-user"] = "?1"
+		}
+	}
+	if p.SendPriority && dest.Priority != "" {
+		h["priority"] = dest.Priority
+	}
+	// Browsers send the origin with CORS requests to other origins and with the requests
+	// that may change state.
+	unsafe := r.Method != "" && r.Method != http.MethodGet && r.Method != http.MethodHead
+	if initiator != "" && ((mode == ModeCORS && site != SiteSameOrigin) || unsafe) {
+		h["origin"] = initiator
+	}
+	return h, nil
+}
+
+// Site returns the Sec-Fetch-Site value of a request from a document of the initiator to the target.
+// Sites are schemeful: http and https URLs of the same registrable domain are cross-site.
+func Site(initiator, target *url.URL) string {
+	switch {
+	case origin(initiator) == origin(target):
+		return SiteSameOrigin
+	case initiator.Scheme == target.Scheme && registrableDomain(initiator.Hostname()) == registrableDomain(target.Hostname()):
+		return SiteSameSite
+	default:
+		return SiteCrossSite
+	}
+}
+
+// Secure reports whether browsers treat the origin of the URL as potentially trustworthy:
+// HTTPS, localhost and loopback addresses. Only such origins get the Sec-Fetch headers,
+// client hints and secure cookies.
+func Secure(u *url.URL) bool {
+	if strings.ToLower(u.Scheme) == "https" {
+		return true
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// origin returns the serialized origin of the URL, without the default port of its scheme.
+func origin(u *url.URL) string {
+	scheme, host, port := strings.ToLower(u.Scheme), strings.ToLower(u.Hostname()), u.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+// registrableDomain returns the eTLD+1 of the host, or the host itself for IP addresses and
+// hosts that are public suffixes, like localhost.
+func registrableDomain(host string) string {
+	host = strings.ToLower(host)
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,318 @@
+package fetch
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name           string
+		browser        string
+		browserVersion string
+		request        Request
+		expected       map[string]string
+		expectedError  error
+	}{
+		{
+			name:           "Chrome typed navigation",
+			browser:        "Chrome",
+			browserVersion: "129.0.6668.58",
+			request:        Request{URL: "https://example.com/", UserActivated: true},
+			expected: map[string]string{
+				"accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+				"accept-encoding":           "gzip, deflate, br, zstd",
+				"sec-fetch-site":            "none",
+				"sec-fetch-mode":            "navigate",
+				"sec-fetch-dest":            "document",
+				"sec-fetch-user":            "?1",
+				"upgrade-insecure-requests": "1",
+				"priority":                  "u=0, i",
+			},
+		},
+		{
+			name:           "Chrome insecure navigation",
+			browser:        "Chrome",
+			browserVersion: "129.0.6668.58",
+			request:        Request{URL: "http://example.com/", UserActivated: true},
+			expected: map[string]string{
+				"accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+				"accept-encoding":           "gzip, deflate",
+				"upgrade-insecure-requests": "1",
+				"priority":                  "u=0, i",
+			},
+		},
+		{
+			name:           "Chrome localhost navigation",
+			browser:        "Chrome",
+			browserVersion: "129.0.6668.58",
+			request:        Request{URL: "http://localhost:8080/", UserActivated: true},
+			expected: map[string]string{
+				"accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+				"accept-encoding":           "gzip, deflate",
+				"sec-fetch-site":            "none",
+				"sec-fetch-mode":            "navigate",
+				"sec-fetch-dest":            "document",
+				"sec-fetch-user":            "?1",
+				"upgrade-insecure-requests": "1",
+				"priority":                  "u=0, i",
+			},
+		},
+		{
+			name:           "Chrome 120 cross-site image",
+			browser:        "Chrome",
+			browserVersion: "120.0",
+			request:        Request{URL: "https://cdn.example.net/a.png", Destination: DestinationImage, Initiator: "https://example.com"},
+			expected: map[string]string{
+				"accept":          "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8",
+				"accept-encoding": "gzip, deflate, br",
+				"sec-fetch-site":  "cross-site",
+				"sec-fetch-mode":  "no-cors",
+				"sec-fetch-dest":  "image",
+			},
+		},
+		{
+			name:           "Edge same-site fetch",
+			browser:        "Edge",
+			browserVersion: "131.0",
+			request:        Request{URL: "https://api.example.com/v1", Destination: DestinationEmpty, Initiator: "https://www.example.com:443"},
+			expected: map[string]string{
+				"accept":          "*/*",
+				"accept-encoding": "gzip, deflate, br, zstd",
+				"sec-fetch-site":  "same-site",
+				"sec-fetch-mode":  "cors",
+				"sec-fetch-dest":  "empty",
+				"priority":        "u=1, i",
+				"origin":          "https://www.example.com",
+			},
+		},
+		{
+			name:           "Chrome same-origin POST",
+			browser:        "Chrome",
+			browserVersion: "131.0",
+			request:        Request{URL: "https://example.com/form", Method: "POST", Destination: DestinationEmpty, Initiator: "https://example.com"},
+			expected: map[string]string{
+				"accept":          "*/*",
+				"accept-encoding": "gzip, deflate, br, zstd",
+				"sec-fetch-site":  "same-origin",
+				"sec-fetch-mode":  "cors",
+				"sec-fetch-dest":  "empty",
+				"priority":        "u=1, i",
+				"origin":          "https://example.com",
+			},
+		},
+		{
+			name:           "Firefox link click",
+			browser:        "Firefox",
+			browserVersion: "133.0",
+			request:        Request{URL: "https://example.org/", Initiator: "https://example.com", UserActivated: true},
+			expected: map[string]string{
+				"accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+				"accept-encoding":           "gzip, deflate, br, zstd",
+				"sec-fetch-site":            "cross-site",
+				"sec-fetch-mode":            "navigate",
+				"sec-fetch-dest":            "document",
+				"sec-fetch-user":            "?1",
+				"upgrade-insecure-requests": "1",
+				"priority":                  "u=0, i",
+			},
+		},
+		{
+			name:           "Firefox 120 same-origin navigation",
+			browser:        "Firefox",
+			browserVersion: "120.0",
+			request:        Request{URL: "https://example.com/next", Initiator: "https://example.com"},
+			expected: map[string]string{
+				"accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+				"accept-encoding":           "gzip, deflate, br",
+				"sec-fetch-site":            "same-origin",
+				"sec-fetch-mode":            "navigate",
+				"sec-fetch-dest":            "document",
+				"upgrade-insecure-requests": "1",
+				"priority":                  "u=0, i",
+			},
+		},
+		{
+			name:           "Safari 17 module script",
+			browser:        "Safari",
+			browserVersion: "17.6",
+			request:        Request{URL: "http://static.example.com/app.js", Destination: DestinationScript, Mode: ModeCORS, Initiator: "https://example.com"},
+			expected: map[string]string{
+				"accept":          "*/*",
+				"accept-encoding": "gzip, deflate",
+				"origin":          "https://example.com",
+			},
+		},
+		{
+			name:           "Safari 18 stylesheet",
+			browser:        "Safari",
+			browserVersion: "18.1",
+			request:        Request{URL: "https://example.com/a.css", Destination: DestinationStyle, Initiator: "https://example.com"},
+			expected: map[string]string{
+				"accept":          "text/css,*/*;q=0.1",
+				"accept-encoding": "gzip, deflate, br",
+				"sec-fetch-site":  "same-origin",
+				"sec-fetch-mode":  "no-cors",
+				"sec-fetch-dest":  "style",
+				"priority":        "u=1",
+			},
+		},
+		{
+			name:           "unknown destination",
+			browser:        "Chrome",
+			browserVersion: "129.0",
+			request:        Request{URL: "https://example.com/", Destination: "object"},
+			expectedError:  ErrUnknownDestination,
+		},
+		{
+			name:           "relative URL",
+			browser:        "Chrome",
+			browserVersion: "129.0",
+			request:        Request{URL: "/index.html"},
+			expectedError:  ErrInvalidURL,
+		},
+		{
+			name:           "unsupported version",
+			browser:        "Safari",
+			browserVersion: "16.0",
+			request:        Request{URL: "https://example.com/"},
+			expectedError:  ErrUnsupportedBrowser,
+		},
+		{
+			name:           "invalid version",
+			browser:        "Firefox",
+			browserVersion: "latest",
+			request:        Request{URL: "https://example.com/"},
+			expectedError:  ErrInvalidBrowserVersion,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := Headers(test.browser, test.browserVersion, test.request)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, h)
+		})
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+		secure         bool
+		expected       string
+		expectedError  error
+	}{
+		{browser: "Chrome", browserVersion: "122.0.6261.94", secure: true, expected: "gzip, deflate, br"},
+		{browser: "Chrome", browserVersion: "123.0", secure: true, expected: "gzip, deflate, br, zstd"},
+		{browser: "Chrome", browserVersion: "123.0", expected: "gzip, deflate"},
+		{browser: "Firefox", browserVersion: "125.0", secure: true, expected: "gzip, deflate, br"},
+		{browser: "Firefox", browserVersion: "126.0", secure: true, expected: "gzip, deflate, br, zstd"},
+		{browser: "Safari", browserVersion: "18.2", secure: true, expected: "gzip, deflate, br"},
+		{browser: "Safari", browserVersion: "10.1", secure: true, expectedError: ErrUnsupportedBrowser},
+		{browser: "Safari", browserVersion: "", expectedError: ErrInvalidBrowserVersion},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			encoding, err := AcceptEncoding(test.browser, test.browserVersion, test.secure)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, encoding)
+		})
+	}
+}
+
+func TestMediaAcceptEncoding(t *testing.T) {
+	h, err := Headers("Chrome", "129.0", Request{URL: "https://example.com/a.mp4", Destination: DestinationVideo})
+	require.NoError(t, err)
+	require.Equal(t, "identity;q=1, *;q=0", h["accept-encoding"])
+}
+
+func TestSite(t *testing.T) {
+	tests := []struct {
+		initiator string
+		target    string
+		expected  string
+	}{
+		{initiator: "https://example.com", target: "https://example.com/a", expected: SiteSameOrigin},
+		{initiator: "https://example.com:443", target: "https://EXAMPLE.com/", expected: SiteSameOrigin},
+		{initiator: "https://www.example.com", target: "https://example.com/", expected: SiteSameSite},
+		{initiator: "https://a.example.co.uk", target: "https://b.example.co.uk/", expected: SiteSameSite},
+		{initiator: "https://a.co.uk", target: "https://b.co.uk/", expected: SiteCrossSite},
+		{initiator: "https://user.github.io", target: "https://other.github.io/", expected: SiteCrossSite},
+		{initiator: "http://example.com", target: "https://example.com/", expected: SiteCrossSite},
+		{initiator: "https://example.com:8443", target: "https://example.com/", expected: SiteSameSite},
+		{initiator: "http://127.0.0.1:8080", target: "http://127.0.0.1:9090/", expected: SiteSameSite},
+		{initiator: "http://localhost", target: "http://localhost/", expected: SiteSameOrigin},
+	}
+
+	for _, test := range tests {
+		t.Run(test.initiator+" "+test.target, func(t *testing.T) {
+			initiator, err := url.Parse(test.initiator)
+			require.NoError(t, err)
+			target, err := url.Parse(test.target)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, Site(initiator, target))
+		})
+	}
+}
+
+func TestSecure(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "https://example.com/", expected: true},
+		{url: "http://example.com/", expected: false},
+		{url: "http://localhost:8080/", expected: true},
+		{url: "http://app.localhost/", expected: true},
+		{url: "http://LOCALHOST./", expected: true},
+		{url: "http://127.0.0.1/", expected: true},
+		{url: "http://[::1]:8080/", expected: true},
+		{url: "http://192.168.1.1/", expected: false},
+		{url: "http://localhost.example.com/", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, Secure(u))
+		})
+	}
+}
+
+func TestDestinations(t *testing.T) {
+	for _, p := range data.Profiles {
+		for dest, mode := range data.Modes {
+			h, err := Headers(p.Browser, strings.TrimPrefix(p.Versions, ">= "), Request{URL: "https://example.com/", Destination: dest})
+			require.NoError(t, err)
+			require.Equal(t, dest, h["sec-fetch-dest"])
+			require.Equal(t, mode, h["sec-fetch-mode"])
+			require.Equal(t, p.SendPriority, h["priority"] != "", "%s %s %s", p.Browser, p.Versions, dest)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	r := Request{Destination: DestinationImage, Initiator: "https://example.com"}
+	got, ok := FromContext(NewContext(context.Background(), r))
+	require.True(t, ok)
+	require.Equal(t, r, got)
+}
@@ -0,0 +1,194 @@
+package fonts
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/chinese-room-solutions/fakebro/internal/random"
+	"github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedPlatform    = fmt.Errorf("unsupported platform")
+	ErrInvalidPlatformVersion = fmt.Errorf("invalid platform version")
+	ErrUnknownPack            = fmt.Errorf("unknown font pack")
+)
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Profile holds the fonts a page detects as installed.
+type Profile struct {
+	// System is the operating system or Linux distribution the fonts come from.
+	System string
+	Packs  []string
+	// Fonts holds the font family names in order.
+	Fonts []string
+}
+
+type Addition struct {
+	Versions string   `yaml:"versions"`
+	Fonts    []string `yaml:"fonts"`
+
+	versions version.Constraints
+}
+
+type System struct {
+	Name      string     `yaml:"name"`
+	Weight    int        `yaml:"weight"`
+	Fonts     []string   `yaml:"fonts"`
+	Additions []Addition `yaml:"additions"`
+}
+
+type Pack struct {
+	Name        string  `yaml:"name"`
+	Probability float64 `yaml:"probability"`
+}
+
+type PlatformData struct {
+	Systems []System `yaml:"systems"`
+	Packs   []Pack   `yaml:"packs"`
+}
+
+type fontData struct {
+	Platforms map[string]*PlatformData `yaml:"platforms"`
+	Packs     map[string][]string      `yaml:"packs"`
+}
+
+var data fontData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for platform, p := range data.Platforms {
+		if len(p.Systems) == 0 {
+			panic(fmt.Sprintf("No systems for %s", platform))
+		}
+		for _, pack := range p.Packs {
+			if _, ok := data.Packs[pack.Name]; !ok {
+				panic(fmt.Sprintf("Unknown font pack %q for %s", pack.Name, platform))
+			}
+		}
+		for _, s := range p.Systems {
+			for i := range s.Additions {
+				s.Additions[i].versions, err = version.NewConstraint(s.Additions[i].Versions)
+				if err != nil {
+					panic(fmt.Sprintf("Invalid addition versions for %s: %v", s.Name, err))
+				}
+			}
+		}
+	}
+}
+
+type options struct {
+	Browser string
+	Packs   []string
+}
+
+type Option func(*options)
+
+// WithBrowser sets the browser the fonts are probed in: "Chrome", "Firefox" or "Safari".
+// Other browsers are treated as Chromium. Without it, Chrome is assumed.
+func WithBrowser(name string) Option {
+	return func(o *options) {
+		o.Browser = name
+	}
+}
+
+// WithPacks installs exactly the given font packs, e.g. "office" or "adobe", instead of
+// picking them with their catalog probabilities. Call it without packs for a bare system.
+func WithPacks(packs ...string) Option {
+	return func(o *options) {
+		o.Packs = append([]string{}, packs...)
+	}
+}
+
+// Packs returns the names of the known font packs in order.
+func Packs() []string {
+	names := make([]string, 0, len(data.Packs))
+	for name := range data.Packs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// GenerateProfile generates the installed fonts of a browser on the platform.
+// The same seed always produces the same fonts.
+func GenerateProfile(seed int64, platform string, platformVersion string, opts ...Option) (*Profile, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var p *PlatformData
+	for name, d := range data.Platforms {
+		if strings.EqualFold(name, platform) {
+			p = d
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+	}
+
+	var v *version.Version
+	if platformVersion != "" {
+		var err error
+		v, err = version.NewVersion(platformVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlatformVersion, platformVersion)
+		}
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	s := random.Pick(r, p.Systems, func(s System) int { return s.Weight })
+
+	var packs []string
+	for _, pack := range p.Packs {
+		if r.Float64() < pack.Probability {
+			packs = append(packs, pack.Name)
+		}
+	}
+	if o.Packs != nil {
+		packs = o.Packs
+	}
+	for _, pack := range packs {
+		if _, ok := data.Packs[pack]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPack, pack)
+		}
+	}
+	if family.Of(o.Browser) == family.Safari {
+		// WebKit only lets pages use the fonts that ship with the system.
+		packs = nil
+	}
+
+	fonts := slices.Clone(s.Fonts)
+	for _, a := range s.Additions {
+		if v != nil && a.versions.Check(v) {
+			fonts = append(fonts, a.Fonts...)
+		}
+	}
+	for _, pack := range packs {
+		fonts = append(fonts, data.Packs[pack]...)
+	}
+	slices.Sort(fonts)
+
+	return &Profile{
+		System: s.Name,
+		Packs:  packs,
+		Fonts:  slices.Compact(fonts),
+	}, nil
+}
@@ -0,0 +1,126 @@
+package fonts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name            string
+		platform        string
+		platformVersion string
+		opts            []Option
+		expectedSystems []string
+		expectedFonts   []string
+		unexpectedFonts []string
+		expectedPacks   []string
+		expectedError   error
+	}{
+		{
+			name:            "Windows 10",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithPacks()},
+			expectedSystems: []string{"Windows"},
+			expectedFonts:   []string{"Segoe UI", "Calibri", "Segoe MDL2 Assets"},
+			unexpectedFonts: []string{"Segoe Fluent Icons", "Aptos"},
+			expectedPacks:   []string{},
+		},
+		{
+			name:            "Windows 11 with Office",
+			platform:        "windows",
+			platformVersion: "15.0.0",
+			opts:            []Option{WithPacks("office")},
+			expectedSystems: []string{"Windows"},
+			expectedFonts:   []string{"Segoe UI", "Segoe Fluent Icons", "Aptos", "Century Gothic"},
+			unexpectedFonts: []string{"Myriad Pro"},
+			expectedPacks:   []string{"office"},
+		},
+		{
+			name:            "Safari on macOS",
+			platform:        "macOS",
+			platformVersion: "14.6.1",
+			opts:            []Option{WithBrowser("Safari"), WithPacks("office", "adobe")},
+			expectedSystems: []string{"macOS"},
+			expectedFonts:   []string{"Helvetica Neue", "Menlo"},
+			unexpectedFonts: []string{"Segoe UI", "Aptos", "Myriad Pro"},
+		},
+		{
+			name:            "Linux",
+			platform:        "Linux",
+			platformVersion: "6.8.12",
+			opts:            []Option{WithPacks()},
+			expectedSystems: []string{"Ubuntu", "Fedora", "Debian", "Arch Linux"},
+			expectedFonts:   []string{"DejaVu Sans", "Noto Sans"},
+			unexpectedFonts: []string{"Arial", "Segoe UI"},
+			expectedPacks:   []string{},
+		},
+		{
+			name:            "iPadOS",
+			platform:        "iPadOS",
+			expectedSystems: []string{"iPadOS"},
+			expectedFonts:   []string{"Helvetica Neue", "Party LET"},
+		},
+		{
+			name:          "unknown pack",
+			platform:      "Windows",
+			opts:          []Option{WithPacks("fancy")},
+			expectedError: ErrUnknownPack,
+		},
+		{
+			name:          "unsupported platform",
+			platform:      "Haiku",
+			expectedError: ErrUnsupportedPlatform,
+		},
+		{
+			name:            "invalid platform version",
+			platform:        "Windows",
+			platformVersion: "eleven",
+			expectedError:   ErrInvalidPlatformVersion,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				p, err := GenerateProfile(seed, test.platform, test.platformVersion, test.opts...)
+				if test.expectedError != nil {
+					require.ErrorIs(t, err, test.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Contains(t, test.expectedSystems, p.System)
+				require.Subset(t, p.Fonts, test.expectedFonts)
+				for _, font := range test.unexpectedFonts {
+					require.NotContains(t, p.Fonts, font)
+				}
+				require.IsIncreasing(t, p.Fonts)
+				if test.expectedPacks != nil {
+					require.ElementsMatch(t, test.expectedPacks, p.Packs)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateProfilePacks(t *testing.T) {
+	seen := map[string]bool{}
+	for seed := int64(0); seed < 200; seed++ {
+		p, err := GenerateProfile(seed, "Windows", "10.0.0")
+		require.NoError(t, err)
+		for _, pack := range p.Packs {
+			seen[pack] = true
+		}
+
+		again, err := GenerateProfile(seed, "Windows", "10.0.0")
+		require.NoError(t, err)
+		require.Equal(t, p, again)
+	}
+	require.Equal(t, map[string]bool{"office": true, "adobe": true}, seen)
+}
+
+func TestPacks(t *testing.T) {
+	require.Equal(t, []string{"adobe", "mscorefonts", "office"}, Packs())
+}
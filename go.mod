@@ -1,14 +1,23 @@
 module github.com/chinese-room-solutions/fakebro
 
-go 1.23.1
+// utls 1.7 and later require Go 1.24. Older releases cannot send the X25519MLKEM768
+// key shares of current Chrome and Firefox ClientHellos.
+go 1.24
 
 require (
+	github.com/andybalholm/brotli v1.0.6
 	github.com/hashicorp/go-version v1.7.0
+	github.com/klauspost/compress v1.17.4
+	github.com/refraction-networking/utls v1.8.2
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/net v0.43.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
 )
@@ -0,0 +1,135 @@
+package http2
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	xhttp2 "golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+var ErrInvalidPreface = fmt.Errorf("invalid HTTP/2 client preface")
+
+// WritePreface writes the client connection preface of the browser: the preface string,
+// the SETTINGS frame, the connection WINDOW_UPDATE and the PRIORITY frames.
+func (p *Profile) WritePreface(w io.Writer) error {
+	if _, err := io.WriteString(w, xhttp2.ClientPreface); err != nil {
+		return err
+	}
+
+	fr := xhttp2.NewFramer(w, nil)
+	settings := make([]xhttp2.Setting, len(p.Settings))
+	for i, s := range p.Settings {
+		settings[i] = xhttp2.Setting{ID: xhttp2.SettingID(s.ID), Val: s.Value}
+	}
+	if err := fr.WriteSettings(settings...); err != nil {
+		return err
+	}
+	if p.WindowUpdate > 0 {
+		if err := fr.WriteWindowUpdate(0, p.WindowUpdate); err != nil {
+			return err
+		}
+	}
+	for _, pr := range p.Priorities {
+		if err := fr.WritePriority(pr.StreamID, priorityParam(pr)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHeaders writes the HPACK encoded header block of a request on the stream,
+// with the HEADERS priority of the browser. Blocks larger than maxFrameSize are continued
+// in CONTINUATION frames.
+func (p *Profile) WriteHeaders(fr *xhttp2.Framer, streamID uint32, block []byte, endStream bool, maxFrameSize int) error {
+	first := block[:min(len(block), maxFrameSize)]
+	block = block[len(first):]
+
+	params := xhttp2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: first,
+		EndStream:     endStream,
+		EndHeaders:    len(block) == 0,
+	}
+	if p.HeaderPriority != nil {
+		params.Priority = priorityParam(*p.HeaderPriority)
+	}
+	if err := fr.WriteHeaders(params); err != nil {
+		return err
+	}
+
+	for len(block) > 0 {
+		fragment := block[:min(len(block), maxFrameSize)]
+		block = block[len(fragment):]
+		if err := fr.WriteContinuation(streamID, len(block) == 0, fragment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func priorityParam(pr Priority) xhttp2.PriorityParam {
+	return xhttp2.PriorityParam{
+		StreamDep: pr.StreamDep,
+		Exclusive: pr.Exclusive,
+		Weight:    uint8(min(max(pr.Weight, 1), 256) - 1),
+	}
+}
+
+// Fingerprint reads the start of an HTTP/2 connection from a client, up to its first request,
+// and returns the Akamai fingerprint of the client.
+func Fingerprint(r io.Reader) (string, error) {
+	preface := make([]byte, len(xhttp2.ClientPreface))
+	if _, err := io.ReadFull(r, preface); err != nil || string(preface) != xhttp2.ClientPreface {
+		return "", ErrInvalidPreface
+	}
+
+	fr := xhttp2.NewFramer(nil, r)
+	fr.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+
+	var (
+		settings     []Setting
+		windowUpdate uint32
+		priorities   []Priority
+	)
+	for {
+		f, err := fr.ReadFrame()
+		if err != nil {
+			return "", fmt.Errorf("failed to read frame: %w", err)
+		}
+
+		switch f := f.(type) {
+		case *xhttp2.SettingsFrame:
+			if f.IsAck() {
+				continue
+			}
+			err = f.ForeachSetting(func(s xhttp2.Setting) error {
+				settings = append(settings, Setting{ID: uint16(s.ID), Value: s.Val})
+				return nil
+			})
+			if err != nil {
+				return "", err
+			}
+		case *xhttp2.WindowUpdateFrame:
+			if f.StreamID == 0 {
+				windowUpdate = f.Increment
+			}
+		case *xhttp2.PriorityFrame:
+			priorities = append(priorities, Priority{
+				StreamID:  f.StreamID,
+				StreamDep: f.StreamDep,
+				Exclusive: f.Exclusive,
+				Weight:    int(f.Weight) + 1,
+			})
+		case *xhttp2.MetaHeadersFrame:
+			var pseudo []string
+			for _, field := range f.PseudoFields() {
+				pseudo = append(pseudo, field.Name)
+			}
+			return akamai(settings, windowUpdate, priorities, pseudo), nil
+		case *xhttp2.GoAwayFrame:
+			return "", errors.New("connection closed before the first request")
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package http2
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	xhttp2 "golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// request writes the preface and a GET request of the profile on the connection.
+func request(p *Profile, conn net.Conn, header http.Header) error {
+	if err := p.WritePreface(conn); err != nil {
+		return err
+	}
+
+	var block bytes.Buffer
+	enc := hpack.NewEncoder(&block)
+	for _, f := range p.HeaderFields("GET", "example.com", "http", "/", header) {
+		if err := enc.WriteField(f); err != nil {
+			return err
+		}
+	}
+	return p.WriteHeaders(xhttp2.NewFramer(conn, nil), 1, block.Bytes(), true, 16)
+}
+
+func TestFingerprint(t *testing.T) {
+	for _, browser := range [][2]string{{"Chrome", "129.0"}, {"Firefox", "133.0"}, {"Safari", "17.4"}, {"Safari", "18.0"}} {
+		t.Run(browser[0]+" "+browser[1], func(t *testing.T) {
+			p, err := GenerateProfile(browser[0], browser[1])
+			require.NoError(t, err)
+
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer l.Close()
+
+			fingerprints := make(chan string, 1)
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					close(fingerprints)
+					return
+				}
+				defer conn.Close()
+				fingerprint, _ := Fingerprint(conn)
+				fingerprints <- fingerprint
+			}()
+
+			conn, err := net.Dial("tcp", l.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, request(p, conn, http.Header{"User-Agent": {"test"}}))
+
+			require.Equal(t, p.Akamai(), <-fingerprints)
+		})
+	}
+}
+
+func TestFingerprintInvalidPreface(t *testing.T) {
+	_, err := Fingerprint(bytes.NewReader([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")))
+	require.ErrorIs(t, err, ErrInvalidPreface)
+}
+
+func TestServerAcceptsProfile(t *testing.T) {
+	p, err := GenerateProfile("Chrome", "129.0")
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go (&xhttp2.Server{}).ServeConn(server, &xhttp2.ServeConnOpts{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-User-Agent", r.UserAgent())
+			_, _ = io.WriteString(w, r.Host)
+		}),
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- request(p, client, http.Header{"User-Agent": {"test"}, "Accept": {"*/*"}})
+	}()
+
+	fr := xhttp2.NewFramer(client, client)
+	fr.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+	var (
+		status, userAgent string
+		body              []byte
+	)
+	for {
+		f, err := fr.ReadFrame()
+		require.NoError(t, err)
+		switch f := f.(type) {
+		case *xhttp2.SettingsFrame:
+			if !f.IsAck() {
+				require.NoError(t, fr.WriteSettingsAck())
+			}
+		case *xhttp2.MetaHeadersFrame:
+			status, userAgent = f.PseudoValue("status"), ""
+			for _, field := range f.RegularFields() {
+				if field.Name == "x-user-agent" {
+					userAgent = field.Value
+				}
+			}
+		case *xhttp2.DataFrame:
+			body = append(body, f.Data()...)
+			if f.StreamEnded() {
+				require.NoError(t, <-errs)
+				require.Equal(t, "200", status)
+				require.Equal(t, "test", userAgent)
+				require.Equal(t, "example.com", string(body))
+				return
+			}
+		}
+	}
+}
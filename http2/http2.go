@@ -0,0 +1,184 @@
+package http2
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/hashicorp/go-version"
+	"golang.org/x/net/http2/hpack"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedBrowser    = fmt.Errorf("unsupported browser")
+	ErrInvalidBrowserVersion = fmt.Errorf("invalid browser version")
+)
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Setting is an HTTP/2 SETTINGS parameter.
+type Setting struct {
+	ID    uint16 `yaml:"id"`
+	Value uint32 `yaml:"value"`
+}
+
+// Priority is an HTTP/2 stream priority, with a weight from 1 to 256.
+type Priority struct {
+	StreamID  uint32 `yaml:"stream_id"`
+	StreamDep uint32 `yaml:"stream_dep"`
+	Exclusive bool   `yaml:"exclusive"`
+	Weight    int    `yaml:"weight"`
+}
+
+// Profile describes how a browser speaks HTTP/2.
+type Profile struct {
+	Browser      string     `yaml:"browser"`
+	Versions     string     `yaml:"versions"`
+	Settings     []Setting  `yaml:"settings"`
+	WindowUpdate uint32     `yaml:"window_update"`
+	Priorities   []Priority `yaml:"priorities"`
+	// HeaderPriority is the priority of the HEADERS frames of requests, or nil if they carry none.
+	HeaderPriority    *Priority `yaml:"header_priority"`
+	PseudoHeaderOrder []string  `yaml:"pseudo_header_order"`
+	HeaderOrder       []string  `yaml:"header_order"`
+
+	versions version.Constraints
+}
+
+type http2Data struct {
+	Profiles []Profile `yaml:"profiles"`
+}
+
+var data http2Data
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for i := range data.Profiles {
+		p := &data.Profiles[i]
+		p.versions, err = version.NewConstraint(p.Versions)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid versions for %s: %v", p.Browser, err))
+		}
+		if len(p.Settings) == 0 || len(p.PseudoHeaderOrder) != 4 {
+			panic(fmt.Sprintf("Incomplete HTTP/2 profile for %s %s", p.Browser, p.Versions))
+		}
+	}
+}
+
+// GenerateProfile returns the HTTP/2 profile of the browser version, e.g. "Chrome" and "129.0".
+func GenerateProfile(browser, browserVersion string) (*Profile, error) {
+	browser, v, err := family.Parse(browser, browserVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBrowserVersion, browserVersion)
+	}
+
+	for _, p := range data.Profiles {
+		if p.Browser == browser && p.versions.Check(v) {
+			p.Settings = slices.Clone(p.Settings)
+			p.Priorities = slices.Clone(p.Priorities)
+			if p.HeaderPriority != nil {
+				priority := *p.HeaderPriority
+				p.HeaderPriority = &priority
+			}
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedBrowser, browser, browserVersion)
+}
+
+// Akamai returns the Akamai HTTP/2 fingerprint of the profile: the settings, the window update
+// increment, the PRIORITY frames and the pseudo-header order, separated by "|".
+func (p *Profile) Akamai() string {
+	return akamai(p.Settings, p.WindowUpdate, p.Priorities, p.PseudoHeaderOrder)
+}
+
+func akamai(settings []Setting, windowUpdate uint32, priorities []Priority, pseudoHeaders []string) string {
+	s := make([]string, len(settings))
+	for i, setting := range settings {
+		s[i] = fmt.Sprintf("%d:%d", setting.ID, setting.Value)
+	}
+
+	w := "00"
+	if windowUpdate > 0 {
+		w = strconv.FormatUint(uint64(windowUpdate), 10)
+	}
+
+	prio := []string{"0"}
+	if len(priorities) > 0 {
+		prio = prio[:0]
+	}
+	for _, pr := range priorities {
+		exclusive := 0
+		if pr.Exclusive {
+			exclusive = 1
+		}
+		prio = append(prio, fmt.Sprintf("%d:%d:%d:%d", pr.StreamID, exclusive, pr.StreamDep, pr.Weight))
+	}
+
+	h := make([]string, len(pseudoHeaders))
+	for i, name := range pseudoHeaders {
+		h[i] = strings.TrimPrefix(name, ":")[:1]
+	}
+
+	return strings.Join([]string{
+		strings.Join(s, ";"), w, strings.Join(prio, ","), strings.Join(h, ","),
+	}, "|")
+}
+
+// HeaderFields returns the header fields of a request in the order of the browser: the
+// pseudo-headers first, then the headers of the profile order and then the others sorted by name.
+// Header names are lower cased and connection specific headers are dropped.
+func (p *Profile) HeaderFields(method, authority, scheme, path string, header http.Header) []hpack.HeaderField {
+	pseudo := map[string]string{":method": method, ":authority": authority, ":scheme": scheme, ":path": path}
+	fields := make([]hpack.HeaderField, 0, 4+len(header))
+	for _, name := range p.PseudoHeaderOrder {
+		if method == http.MethodConnect && (name == ":scheme" || name == ":path") {
+			continue
+		}
+		fields = append(fields, hpack.HeaderField{Name: name, Value: pseudo[name]})
+	}
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		lower := strings.ToLower(name)
+		switch lower {
+		case "connection", "keep-alive", "proxy-connection", "transfer-encoding", "upgrade", "host":
+			continue
+		}
+		names = append(names, name)
+	}
+	rank := func(name string) int {
+		if i := slices.Index(p.HeaderOrder, strings.ToLower(name)); i >= 0 {
+			return i
+		}
+		return len(p.HeaderOrder)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := rank(a) - rank(b); c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			fields = append(fields, hpack.HeaderField{Name: strings.ToLower(name), Value: value})
+		}
+	}
+	return fields
+}
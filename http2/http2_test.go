@@ -0,0 +1,156 @@
+package http2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2/hpack"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+		expectedAkamai string
+		expectedError  error
+	}{
+		{
+			browser:        "Chrome",
+			browserVersion: "129.0",
+			expectedAkamai: "1:65536;2:0;4:6291456;6:262144|15663105|0|m,a,s,p",
+		},
+		{
+			browser:        "Edge",
+			browserVersion: "120.0.2210.91",
+			expectedAkamai: "1:65536;2:0;4:6291456;6:262144|15663105|0|m,a,s,p",
+		},
+		{
+			browser:        "Firefox",
+			browserVersion: "133.0",
+			expectedAkamai: "1:65536;2:0;4:131072;5:16384|12517377|0|m,p,a,s",
+		},
+		{
+			browser:        "Safari",
+			browserVersion: "17.4",
+			expectedAkamai: "2:0;4:4194304;3:100|10485760|0|m,s,p,a",
+		},
+		{
+			browser:        "Safari",
+			browserVersion: "18.0",
+			expectedAkamai: "2:0;3:100;4:2097152;9:1|10420225|0|m,s,a,p",
+		},
+		{
+			browser:        "Safari",
+			browserVersion: "15.6",
+			expectedError:  ErrUnsupportedBrowser,
+		},
+		{
+			browser:        "Chrome",
+			browserVersion: "",
+			expectedError:  ErrInvalidBrowserVersion,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			p, err := GenerateProfile(test.browser, test.browserVersion)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedAkamai, p.Akamai())
+		})
+	}
+}
+
+func TestGenerateProfileCopies(t *testing.T) {
+	p, err := GenerateProfile("Chrome", "129.0")
+	require.NoError(t, err)
+	p.Settings[0].Value = 1
+	p.HeaderPriority.Weight = 1
+
+	again, err := GenerateProfile("Chrome", "129.0")
+	require.NoError(t, err)
+	require.Equal(t, uint32(65536), again.Settings[0].Value)
+	require.Equal(t, 256, again.HeaderPriority.Weight)
+}
+
+func TestAkamaiPriorities(t *testing.T) {
+	p := &Profile{
+		Settings:     []Setting{{ID: 1, Value: 65536}, {ID: 4, Value: 131072}, {ID: 5, Value: 16384}},
+		WindowUpdate: 12517377,
+		Priorities: []Priority{
+			{StreamID: 3, Weight: 201},
+			{StreamID: 9, StreamDep: 7, Weight: 1},
+			{StreamID: 11, StreamDep: 3, Exclusive: true, Weight: 1},
+		},
+		PseudoHeaderOrder: []string{":method", ":path", ":authority", ":scheme"},
+	}
+	require.Equal(t, "1:65536;4:131072;5:16384|12517377|3:0:0:201,9:0:7:1,11:1:3:1|m,p,a,s", p.Akamai())
+
+	p.WindowUpdate = 0
+	require.Equal(t, "00", p.Akamai()[len("1:65536;4:131072;5:16384|"):][:2])
+}
+
+func TestHeaderFields(t *testing.T) {
+	header := http.Header{
+		"Accept-Language": {"en-US,en;q=0.9"},
+		"User-Agent":      {"Mozilla/5.0"},
+		"Accept":          {"text/html"},
+		"X-Custom":        {"b"},
+		"A-Custom":        {"a"},
+		"Connection":      {"keep-alive"},
+		"Cookie":          {"a=1", "b=2"},
+	}
+
+	tests := []struct {
+		browser  string
+		version  string
+		expected []hpack.HeaderField
+	}{
+		{
+			browser: "Chrome",
+			version: "129.0",
+			expected: []hpack.HeaderField{
+				{Name: ":method", Value: "GET"},
+				{Name: ":authority", Value: "example.com"},
+				{Name: ":scheme", Value: "https"},
+				{Name: ":path", Value: "/"},
+				{Name: "user-agent", Value: "Mozilla/5.0"},
+				{Name: "accept", Value: "text/html"},
+				{Name: "accept-language", Value: "en-US,en;q=0.9"},
+				{Name: "cookie", Value: "a=1"},
+				{Name: "cookie", Value: "b=2"},
+				{Name: "a-custom", Value: "a"},
+				{Name: "x-custom", Value: "b"},
+			},
+		},
+		{
+			browser: "Firefox",
+			version: "133.0",
+			expected: []hpack.HeaderField{
+				{Name: ":method", Value: "GET"},
+				{Name: ":path", Value: "/"},
+				{Name: ":authority", Value: "example.com"},
+				{Name: ":scheme", Value: "https"},
+				{Name: "user-agent", Value: "Mozilla/5.0"},
+				{Name: "accept", Value: "text/html"},
+				{Name: "accept-language", Value: "en-US,en;q=0.9"},
+				{Name: "cookie", Value: "a=1"},
+				{Name: "cookie", Value: "b=2"},
+				{Name: "a-custom", Value: "a"},
+				{Name: "x-custom", Value: "b"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser, func(t *testing.T) {
+			p, err := GenerateProfile(test.browser, test.version)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, p.HeaderFields("GET", "example.com", "https", "/", header))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package family
+
+import (
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Browser families the catalogs describe.
+const (
+	Chrome  = "chrome"
+	Firefox = "firefox"
+	Safari  = "safari"
+)
+
+// Of returns the family of the browser name, e.g. Firefox for "Firefox". Browsers other than
+// Firefox and Safari, like Edge and Opera, are Chromium based and belong to Chrome.
+func Of(browser string) string {
+	browser = strings.ToLower(browser)
+	if browser != Firefox && browser != Safari {
+		return Chrome
+	}
+	return browser
+}
+
+// Parse returns the family of the browser name and its parsed version.
+func Parse(browser, browserVersion string) (string, *version.Version, error) {
+	v, err := version.NewVersion(browserVersion)
+	if err != nil {
+		return "", nil, err
+	}
+	return Of(browser), v, nil
+}
@@ -0,0 +1,37 @@
+package family
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		browser         string
+		browserVersion  string
+		expected        string
+		expectedVersion string
+		expectedError   bool
+	}{
+		{browser: "Chrome", browserVersion: "131.0.6778.85", expected: Chrome, expectedVersion: "131.0.6778.85"},
+		{browser: "Firefox", browserVersion: "133.0", expected: Firefox, expectedVersion: "133.0"},
+		{browser: "SAFARI", browserVersion: "17.4", expected: Safari, expectedVersion: "17.4"},
+		{browser: "Edge", browserVersion: "131.0", expected: Chrome, expectedVersion: "131.0"},
+		{browser: "", browserVersion: "120", expected: Chrome, expectedVersion: "120"},
+		{browser: "Chrome", browserVersion: "latest", expectedError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			f, v, err := Parse(test.browser, test.browserVersion)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, f)
+			require.Equal(t, test.expectedVersion, v.Original())
+		})
+	}
+}
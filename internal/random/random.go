@@ -0,0 +1,36 @@
+package random
+
+import "math/rand"
+
+// Weighted is a catalog value with its weight, e.g. {value: 48000, weight: 3}.
+type Weighted[T any] struct {
+	Value  T   `yaml:"value"`
+	Weight int `yaml:"weight"`
+}
+
+// WeightOf returns the weight of the value, for Pick.
+func WeightOf[T any](w Weighted[T]) int {
+	return w.Weight
+}
+
+// Pick returns a random value with the given weights, or the zero value if there are none.
+// Weights below 1 count as 1, so that catalog entries without a weight stay possible.
+func Pick[T any](r *rand.Rand, values []T, weight func(T) int) T {
+	total := 0
+	for _, v := range values {
+		total += max(weight(v), 1)
+	}
+	if total == 0 {
+		var zero T
+		return zero
+	}
+
+	n := r.Intn(total)
+	for _, v := range values {
+		n -= max(weight(v), 1)
+		if n < 0 {
+			return v
+		}
+	}
+	return values[len(values)-1]
+}
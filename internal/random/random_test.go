@@ -0,0 +1,28 @@
+package random
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPick(t *testing.T) {
+	type value struct {
+		name   string
+		weight int
+	}
+	weight := func(v value) int { return v.weight }
+
+	r := rand.New(rand.NewSource(1))
+	require.Equal(t, value{}, Pick(r, nil, weight))
+
+	counts := map[string]int{}
+	values := []value{{name: "heavy", weight: 9}, {name: "light", weight: 1}, {name: "unweighted"}}
+	for i := 0; i < 11000; i++ {
+		counts[Pick(r, values, weight).name]++
+	}
+	require.InDelta(t, 9000, counts["heavy"], 300)
+	require.InDelta(t, 1000, counts["light"], 200)
+	require.InDelta(t, 1000, counts["unweighted"], 200)
+}
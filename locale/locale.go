@@ -0,0 +1,190 @@
+package locale
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	// The time zone database is embedded so that offsets do not depend on the host.
+	_ "time/tzdata"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/chinese-room-solutions/fakebro/internal/random"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnsupportedCountry = fmt.Errorf("unsupported country")
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Profile holds the languages and time zone of an identity.
+type Profile struct {
+	Country string
+	// Languages is navigator.languages, most preferred first.
+	Languages      []string
+	AcceptLanguage string
+	// TimeZone is the IANA time zone Intl.DateTimeFormat().resolvedOptions().timeZone reports.
+	TimeZone string
+}
+
+type languages struct {
+	Languages []string `yaml:"languages"`
+	Weight    int      `yaml:"weight"`
+}
+
+type country struct {
+	Locales   []languages               `yaml:"locales"`
+	Timezones []random.Weighted[string] `yaml:"timezones"`
+}
+
+type localeData struct {
+	Default   string              `yaml:"default"`
+	Countries map[string]*country `yaml:"countries"`
+}
+
+var (
+	data      localeData
+	locations = map[string]*time.Location{}
+)
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	if _, ok := data.Countries[data.Default]; !ok {
+		panic(fmt.Sprintf("Unknown default country %q", data.Default))
+	}
+	for code, c := range data.Countries {
+		if len(c.Locales) == 0 || len(c.Timezones) == 0 {
+			panic(fmt.Sprintf("Incomplete locale data for %s", code))
+		}
+		for _, tz := range c.Timezones {
+			locations[tz.Value], err = time.LoadLocation(tz.Value)
+			if err != nil {
+				panic(fmt.Sprintf("Invalid time zone for %s: %v", code, err))
+			}
+		}
+	}
+}
+
+type options struct {
+	Country string
+	Browser string
+}
+
+type Option func(*options)
+
+// WithCountry generates the locale of the given ISO 3166-1 alpha-2 country, e.g. the country of
+// the exit IP. Without it, the default country of the catalog is used.
+func WithCountry(code string) Option {
+	return func(o *options) {
+		o.Country = code
+	}
+}
+
+// WithBrowser sets the browser that formats the languages: "Chrome", "Firefox" or "Safari".
+// Other browsers are treated as Chromium. Without it, Chrome is assumed.
+func WithBrowser(name string) Option {
+	return func(o *options) {
+		o.Browser = name
+	}
+}
+
+// Countries returns the supported country codes in order.
+func Countries() []string {
+	codes := make([]string, 0, len(data.Countries))
+	for code := range data.Countries {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// GenerateProfile generates the languages and time zone of a browser in the country.
+// The same seed always produces the same values.
+func GenerateProfile(seed int64, opts ...Option) (*Profile, error) {
+	o := options{Country: data.Default}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	browser := family.Of(o.Browser)
+
+	code := strings.ToUpper(o.Country)
+	c, ok := data.Countries[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCountry, o.Country)
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	langs := slices.Clone(random.Pick(r, c.Locales, func(l languages) int { return l.Weight }).Languages)
+	tz := random.Pick(r, c.Timezones, random.WeightOf[string]).Value
+
+	var header []string
+	switch browser {
+	case family.Safari:
+		// Safari only exposes the primary language and adds its base language to the header.
+		langs = langs[:1]
+		header = langs
+		if base, _, ok := strings.Cut(langs[0], "-"); ok {
+			header = append(header, base+";q=0.9")
+		}
+	case family.Firefox:
+		header = qualify(langs, firefoxQuality)
+	default:
+		header = qualify(langs, chromeQuality)
+	}
+
+	return &Profile{
+		Country:        code,
+		Languages:      langs,
+		AcceptLanguage: strings.Join(header, ","),
+		TimeZone:       tz,
+	}, nil
+}
+
+// TimezoneOffset returns the offset Date.prototype.getTimezoneOffset reports at t, in minutes
+// from local time to UTC.
+func (p *Profile) TimezoneOffset(t time.Time) int {
+	loc, ok := locations[p.TimeZone]
+	if !ok {
+		return 0
+	}
+	_, offset := t.In(loc).Zone()
+	return -offset / 60
+}
+
+// qualify adds the q-value of every language but the first.
+func qualify(langs []string, quality func(i, n int) string) []string {
+	header := make([]string, len(langs))
+	for i, lang := range langs {
+		header[i] = lang
+		if i > 0 {
+			header[i] += ";q=" + quality(i, len(langs))
+		}
+	}
+	return header
+}
+
+// chromeQuality decreases by 0.1 for every language, down to 0.1.
+func chromeQuality(i, _ int) string {
+	return strconv.FormatFloat(float64(max(10-i, 1))/10, 'f', 1, 64)
+}
+
+// firefoxQuality spreads the languages evenly below 1, with two decimals from 10 languages on.
+func firefoxQuality(i, n int) string {
+	if n < 10 {
+		return strconv.FormatFloat(float64((10*(n-i)+n/2)/n)/10, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(float64((100*(n-i)+n/2)/n)/100, 'f', 2, 64)
+}
@@ -0,0 +1,150 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chinese-room-solutions/fakebro/internal/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []Option
+		expectedCountry   string
+		expectedPrimary   []string
+		expectedTimezones []string
+		expectedError     error
+	}{
+		{
+			name:              "default country",
+			expectedCountry:   "US",
+			expectedPrimary:   []string{"en-US", "es-US"},
+			expectedTimezones: []string{"America/New_York", "America/Chicago", "America/Denver", "America/Phoenix", "America/Los_Angeles", "America/Anchorage", "Pacific/Honolulu"},
+		},
+		{
+			name:              "Germany",
+			opts:              []Option{WithCountry("DE")},
+			expectedCountry:   "DE",
+			expectedPrimary:   []string{"de-DE", "de"},
+			expectedTimezones: []string{"Europe/Berlin"},
+		},
+		{
+			name:              "lower case country",
+			opts:              []Option{WithCountry("jp"), WithBrowser("Firefox")},
+			expectedCountry:   "JP",
+			expectedPrimary:   []string{"ja", "ja-JP"},
+			expectedTimezones: []string{"Asia/Tokyo"},
+		},
+		{
+			name:          "unknown country",
+			opts:          []Option{WithCountry("XX")},
+			expectedError: ErrUnsupportedCountry,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				p, err := GenerateProfile(seed, test.opts...)
+				if test.expectedError != nil {
+					require.ErrorIs(t, err, test.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, test.expectedCountry, p.Country)
+				require.Contains(t, test.expectedPrimary, p.Languages[0])
+				require.Contains(t, test.expectedTimezones, p.TimeZone)
+				require.True(t, strings.HasPrefix(p.AcceptLanguage, p.Languages[0]))
+
+				again, err := GenerateProfile(seed, test.opts...)
+				require.NoError(t, err)
+				require.Equal(t, p, again)
+			}
+		})
+	}
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		browser           string
+		languages         []string
+		expectedLanguages []string
+		expectedHeader    string
+	}{
+		{
+			browser:           "Chrome",
+			languages:         []string{"de-DE", "de", "en-US", "en"},
+			expectedLanguages: []string{"de-DE", "de", "en-US", "en"},
+			expectedHeader:    "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7",
+		},
+		{
+			browser:           "Edge",
+			languages:         []string{"ja"},
+			expectedLanguages: []string{"ja"},
+			expectedHeader:    "ja",
+		},
+		{
+			browser:           "Firefox",
+			languages:         []string{"en-US", "en"},
+			expectedLanguages: []string{"en-US", "en"},
+			expectedHeader:    "en-US,en;q=0.5",
+		},
+		{
+			browser:           "Firefox",
+			languages:         []string{"de-DE", "de", "en-US", "en"},
+			expectedLanguages: []string{"de-DE", "de", "en-US", "en"},
+			expectedHeader:    "de-DE,de;q=0.8,en-US;q=0.5,en;q=0.3",
+		},
+		{
+			browser:           "Safari",
+			languages:         []string{"fr-CA", "fr", "en-US", "en"},
+			expectedLanguages: []string{"fr-CA"},
+			expectedHeader:    "fr-CA,fr;q=0.9",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.expectedHeader, func(t *testing.T) {
+			data.Countries["ZZ"] = &country{
+				Locales:   []languages{{Languages: test.languages}},
+				Timezones: []random.Weighted[string]{{Value: "UTC"}},
+			}
+			locations["UTC"] = time.UTC
+			defer delete(data.Countries, "ZZ")
+
+			p, err := GenerateProfile(1, WithCountry("ZZ"), WithBrowser(test.browser))
+			require.NoError(t, err)
+			require.Equal(t, test.expectedLanguages, p.Languages)
+			require.Equal(t, test.expectedHeader, p.AcceptLanguage)
+		})
+	}
+}
+
+func TestTimezoneOffset(t *testing.T) {
+	tests := []struct {
+		timezone string
+		time     time.Time
+		expected int
+	}{
+		{timezone: "America/New_York", time: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), expected: 300},
+		{timezone: "America/New_York", time: time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC), expected: 240},
+		{timezone: "Europe/Berlin", time: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), expected: -60},
+		{timezone: "Asia/Kolkata", time: time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC), expected: -330},
+		{timezone: "Australia/Sydney", time: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), expected: -660},
+	}
+
+	for _, test := range tests {
+		p := &Profile{TimeZone: test.timezone}
+		require.Equal(t, test.expected, p.TimezoneOffset(test.time), "%s at %s", test.timezone, test.time)
+	}
+}
+
+func TestCountries(t *testing.T) {
+	countries := Countries()
+	require.Contains(t, countries, "US")
+	require.Contains(t, countries, "DE")
+	require.IsIncreasing(t, countries)
+}
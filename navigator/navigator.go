@@ -0,0 +1,199 @@
+package navigator
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/random"
+	"github.com/chinese-room-solutions/fakebro/useragent"
+	"github.com/chinese-room-solutions/fakebro/webgl"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnsupportedPlatform = fmt.Errorf("unsupported platform")
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Profile holds the navigator values a page observes.
+type Profile struct {
+	Platform            string
+	Vendor              string
+	HardwareConcurrency int
+	// DeviceMemory is the memory in GiB rounded down to a power of two and capped at 8,
+	// or 0 for browsers that do not expose navigator.deviceMemory.
+	DeviceMemory   float64
+	MaxTouchPoints int
+	Language       string
+	Languages      []string
+}
+
+type platformValue[T any] struct {
+	Platform string `yaml:"platform"`
+	Arch     string `yaml:"arch"`
+
+	random.Weighted[T] `yaml:",inline"`
+}
+
+type hardware struct {
+	Match    string `yaml:"match"`
+	Platform string `yaml:"platform"`
+	Tier     string `yaml:"tier"`
+	Cores    []int  `yaml:"cores"`
+	Memory   []int  `yaml:"memory"`
+
+	match *regexp.Regexp
+}
+
+type navigatorData struct {
+	Platforms   []platformValue[string] `yaml:"platforms"`
+	TouchPoints []platformValue[int]    `yaml:"touch_points"`
+	Hardware    []hardware              `yaml:"hardware"`
+}
+
+var data navigatorData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for i, h := range data.Hardware {
+		if len(h.Cores) == 0 || len(h.Memory) == 0 {
+			panic(fmt.Sprintf("Incomplete hardware entry %d in data.yml", i))
+		}
+		if h.Match != "" {
+			data.Hardware[i].match, err = regexp.Compile(h.Match)
+			if err != nil {
+				panic(fmt.Sprintf("Invalid hardware match %q: %v", h.Match, err))
+			}
+		}
+	}
+}
+
+type options struct {
+	GPU       *webgl.GPU
+	Languages []string
+}
+
+type Option func(*options)
+
+// WithGPU sizes the CPU and memory for the GPU of the identity, e.g. the one returned by
+// webgl.GenerateGPU. Without it, a mid tier GPU is assumed.
+func WithGPU(gpu webgl.GPU) Option {
+	return func(o *options) {
+		o.GPU = &gpu
+	}
+}
+
+// WithLanguages sets navigator.languages, most preferred first. Without it, ["en-US", "en"] is used.
+func WithLanguages(languages ...string) Option {
+	return func(o *options) {
+		o.Languages = languages
+	}
+}
+
+func (h *hardware) matches(platform string, gpu webgl.GPU) bool {
+	if h.match != nil && !h.match.MatchString(gpu.Model) {
+		return false
+	}
+	if h.Platform != "" && !strings.EqualFold(h.Platform, platform) {
+		return false
+	}
+	return h.Tier == "" || h.Tier == gpu.Tier
+}
+
+// GenerateProfile generates the navigator values of the browser in the user agent.
+// The same seed, user agent and GPU always produce the same values.
+func GenerateProfile(seed int64, ua *useragent.UserAgent, opts ...Option) (*Profile, error) {
+	o := options{Languages: []string{"en-US", "en"}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	gpu := webgl.GPU{Tier: "mid"}
+	if o.GPU != nil {
+		gpu = *o.GPU
+	}
+
+	var platform, arch string
+	if t, ok := ua.Token(useragent.START_PLATFORM, useragent.END_PLATFORM); ok {
+		platform = t.String()
+	}
+	if t, ok := ua.Token(useragent.START_ARCH, useragent.END_ARCH); ok {
+		arch = t.String()
+	}
+
+	platforms := filter(data.Platforms, platform, arch)
+	touchPoints := filter(data.TouchPoints, platform, arch)
+	i := slices.IndexFunc(data.Hardware, func(h hardware) bool {
+		return h.matches(platform, gpu)
+	})
+	if len(platforms) == 0 || len(touchPoints) == 0 || i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+	}
+	h := data.Hardware[i]
+
+	r := rand.New(rand.NewSource(seed))
+	profile := &Profile{
+		Platform:            random.Pick(r, platforms, random.WeightOf[string]).Value,
+		MaxTouchPoints:      random.Pick(r, touchPoints, random.WeightOf[int]).Value,
+		HardwareConcurrency: h.Cores[r.Intn(len(h.Cores))],
+		Languages:           slices.Clone(o.Languages),
+	}
+	memory := h.Memory[r.Intn(len(h.Memory))]
+	if len(profile.Languages) > 0 {
+		profile.Language = profile.Languages[0]
+	}
+
+	_, safari := ua.Token(useragent.START_SAFARI, useragent.END_SAFARI)
+	switch {
+	case safari || platform == "iOS" || platform == "iPadOS":
+		// Every browser on iOS and iPadOS is WebKit, which reports at most 8 cores
+		// and has no navigator.deviceMemory.
+		profile.Vendor = "Apple Computer, Inc."
+		profile.HardwareConcurrency = min(profile.HardwareConcurrency, 8)
+	default:
+		profile.Vendor = "Google Inc."
+		profile.DeviceMemory = deviceMemory(memory)
+	}
+
+	return profile, nil
+}
+
+// deviceMemory returns the navigator.deviceMemory Chrome reports for the memory in GiB.
+func deviceMemory(gib int) float64 {
+	m := 8.0
+	for m > 0.25 && m > float64(gib) {
+		m /= 2
+	}
+	return m
+}
+
+// filter returns the entries for the platform and sec-ch-ua-arch value. Entries for the
+// architecture win over the entries without one.
+func filter[T any](values []platformValue[T], platform, arch string) []random.Weighted[T] {
+	var specific, generic []random.Weighted[T]
+	for _, v := range values {
+		switch {
+		case !strings.EqualFold(v.Platform, platform):
+		case v.Arch == "":
+			generic = append(generic, v.Weighted)
+		case strings.EqualFold(v.Arch, arch):
+			specific = append(specific, v.Weighted)
+		}
+	}
+	if len(specific) > 0 {
+		return specific
+	}
+	return generic
+}
@@ -0,0 +1,149 @@
+package navigator
+
+import (
+	"testing"
+
+	"github.com/chinese-room-solutions/fakebro/useragent"
+	"github.com/chinese-room-solutions/fakebro/webgl"
+	"github.com/stretchr/testify/require"
+)
+
+func userAgent(seed int64, platform useragent.TokenType) *useragent.UserAgent {
+	return useragent.NewUserAgent(20, seed, useragent.WithCondition(func(tt useragent.TokenType) bool {
+		return !useragent.In(tt, useragent.START_PLATFORM, useragent.END_PLATFORM) || tt == platform
+	}))
+}
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name              string
+		platform          useragent.TokenType
+		opts              []Option
+		expectedPlatforms []string
+		expectedVendor    string
+		expectedTouch     []int
+		expectedCores     []int
+		expectedMemory    []float64
+	}{
+		{
+			name:              "Windows with an RTX 4090",
+			platform:          useragent.PLATFORM_WINDOWS,
+			opts:              []Option{WithGPU(webgl.GPU{Model: "GeForce RTX 4090", Tier: "high"})},
+			expectedPlatforms: []string{"Win32"},
+			expectedVendor:    "Google Inc.",
+			expectedTouch:     []int{0, 10},
+			expectedCores:     []int{8, 12, 16, 20, 24, 32},
+			expectedMemory:    []float64{8},
+		},
+		{
+			name:              "Windows with a low tier GPU",
+			platform:          useragent.PLATFORM_WINDOWS,
+			opts:              []Option{WithGPU(webgl.GPU{Model: "HD Graphics 3000", Tier: "low"})},
+			expectedPlatforms: []string{"Win32"},
+			expectedVendor:    "Google Inc.",
+			expectedTouch:     []int{0, 10},
+			expectedCores:     []int{2, 4, 8},
+			expectedMemory:    []float64{4, 8},
+		},
+		{
+			name:              "Linux without GPU",
+			platform:          useragent.PLATFORM_LINUX,
+			expectedPlatforms: []string{"Linux x86_64", "Linux aarch64"},
+			expectedVendor:    "Google Inc.",
+			expectedTouch:     []int{0},
+			expectedCores:     []int{4, 6, 8, 12, 16},
+			expectedMemory:    []float64{8},
+		},
+		{
+			name:              "Android",
+			platform:          useragent.PLATFORM_ANDROID,
+			opts:              []Option{WithGPU(webgl.GPU{Model: "Adreno (TM) 740", Tier: "high"})},
+			expectedPlatforms: []string{"Linux armv8l", "Linux aarch64"},
+			expectedVendor:    "Google Inc.",
+			expectedTouch:     []int{5},
+			expectedCores:     []int{8},
+			expectedMemory:    []float64{4, 8},
+		},
+		{
+			name:              "macOS with an M3 Max",
+			platform:          useragent.PLATFORM_MACOS,
+			opts:              []Option{WithGPU(webgl.GPU{Model: "Apple M3 Max", Tier: "high"})},
+			expectedPlatforms: []string{"MacIntel"},
+			expectedVendor:    "",
+			expectedTouch:     []int{0},
+			expectedCores:     []int{8, 14, 16},
+			expectedMemory:    []float64{0, 8},
+		},
+		{
+			name:              "iOS",
+			platform:          useragent.PLATFORM_IOS,
+			opts:              []Option{WithGPU(webgl.GPU{Model: "Apple GPU", Tier: "high"})},
+			expectedPlatforms: []string{"iPhone"},
+			expectedVendor:    "Apple Computer, Inc.",
+			expectedTouch:     []int{5},
+			expectedCores:     []int{6},
+			expectedMemory:    []float64{0},
+		},
+		{
+			name:              "iPadOS",
+			platform:          useragent.PLATFORM_IPADOS,
+			expectedPlatforms: []string{"MacIntel"},
+			expectedVendor:    "Apple Computer, Inc.",
+			expectedTouch:     []int{5},
+			expectedCores:     []int{6, 8},
+			expectedMemory:    []float64{0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				ua := userAgent(seed, test.platform)
+				p, err := GenerateProfile(seed, ua, test.opts...)
+				require.NoError(t, err)
+
+				require.Contains(t, test.expectedPlatforms, p.Platform)
+				if test.expectedVendor != "" {
+					require.Equal(t, test.expectedVendor, p.Vendor)
+				}
+				require.Contains(t, test.expectedTouch, p.MaxTouchPoints)
+				require.Contains(t, test.expectedCores, p.HardwareConcurrency)
+				require.Contains(t, test.expectedMemory, p.DeviceMemory)
+				require.Equal(t, "en-US", p.Language)
+				require.Equal(t, []string{"en-US", "en"}, p.Languages)
+
+				_, safari := ua.Token(useragent.START_SAFARI, useragent.END_SAFARI)
+				require.Equal(t, safari, p.DeviceMemory == 0)
+
+				again, err := GenerateProfile(seed, ua, test.opts...)
+				require.NoError(t, err)
+				require.Equal(t, p, again)
+			}
+		})
+	}
+}
+
+func TestGenerateProfileWithLanguages(t *testing.T) {
+	p, err := GenerateProfile(1, userAgent(1, useragent.PLATFORM_WINDOWS), WithLanguages("de-DE", "de", "en"))
+	require.NoError(t, err)
+	require.Equal(t, "de-DE", p.Language)
+	require.Equal(t, []string{"de-DE", "de", "en"}, p.Languages)
+}
+
+func TestDeviceMemory(t *testing.T) {
+	tests := []struct {
+		gib      int
+		expected float64
+	}{
+		{gib: 0, expected: 0.25},
+		{gib: 1, expected: 1},
+		{gib: 3, expected: 2},
+		{gib: 6, expected: 4},
+		{gib: 8, expected: 8},
+		{gib: 64, expected: 8},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, deviceMemory(test.gib), "%d GiB", test.gib)
+	}
+}
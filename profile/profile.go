@@ -0,0 +1,193 @@
+package profile
+
+import (
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/audio"
+	"github.com/chinese-room-solutions/fakebro/canvas"
+	"github.com/chinese-room-solutions/fakebro/cookiejar"
+	"github.com/chinese-room-solutions/fakebro/fetch"
+	"github.com/chinese-room-solutions/fakebro/fonts"
+	"github.com/chinese-room-solutions/fakebro/http2"
+	"github.com/chinese-room-solutions/fakebro/locale"
+	"github.com/chinese-room-solutions/fakebro/navigator"
+	"github.com/chinese-room-solutions/fakebro/screen"
+	"github.com/chinese-room-solutions/fakebro/tls"
+	"github.com/chinese-room-solutions/fakebro/useragent"
+	"github.com/chinese-room-solutions/fakebro/webgl"
+)
+
+// userAgentLength is the number of tokens generated for the user agent.
+const userAgentLength = 20
+
+// Profile is a browser identity: the user agent headers and the fingerprinting surfaces
+// generated for the same platform, browser and hardware.
+type Profile struct {
+	Headers         map[string]string
+	Platform        string
+	PlatformVersion string
+	Arch            string
+	Model           string
+	Browser         string
+	BrowserVersion  string
+
+	WebGL     *webgl.Profile
+	Canvas    *canvas.Profile
+	Audio     *audio.Profile
+	Screen    *screen.Profile
+	Navigator *navigator.Profile
+	Locale    *locale.Profile
+	Fonts     *fonts.Profile
+	TLS       *tls.ClientHelloSpec
+	HTTP2     *http2.Profile
+	Cookies   *cookiejar.Policy
+}
+
+type options struct {
+	UserAgent []useragent.Option
+	WebGL     []webgl.Option
+	Country   string
+}
+
+type Option func(*options)
+
+// WithUserAgent constrains the generated user agent, e.g. to a platform.
+func WithUserAgent(opts ...useragent.Option) Option {
+	return func(o *options) {
+		o.UserAgent = append(o.UserAgent, opts...)
+	}
+}
+
+// WithWebGL constrains the generated GPU, e.g. to drop virtual machine adapters.
+func WithWebGL(opts ...webgl.Option) Option {
+	return func(o *options) {
+		o.WebGL = append(o.WebGL, opts...)
+	}
+}
+
+// WithCountry generates the languages and time zone of the given ISO 3166-1 alpha-2 country,
+// e.g. the country of the exit IP.
+func WithCountry(code string) Option {
+	return func(o *options) {
+		o.Country = code
+	}
+}
+
+var platformVersions = [][2]useragent.TokenType{
+	{useragent.START_LINUX_PLATFORM_VERSION, useragent.END_LINUX_PLATFORM_VERSION},
+	{useragent.START_MACOS_PLATFORM_VERSION, useragent.END_MACOS_PLATFORM_VERSION},
+	{useragent.START_WINDOWS_PLATFORM_VERSION, useragent.END_WINDOWS_PLATFORM_VERSION},
+	{useragent.START_ANDROID_PLATFORM_VERSION, useragent.END_ANDROID_PLATFORM_VERSION},
+	{useragent.START_IOS_PLATFORM_VERSION, useragent.END_IOS_PLATFORM_VERSION},
+	{useragent.START_CHROMEOS_PLATFORM_VERSION, useragent.END_CHROMEOS_PLATFORM_VERSION},
+	{useragent.START_IPADOS_PLATFORM_VERSION, useragent.END_IPADOS_PLATFORM_VERSION},
+}
+
+// Generate generates a profile. The same seed and options always produce the same profile.
+func Generate(seed int64, opts ...Option) (*Profile, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ua := useragent.NewUserAgent(userAgentLength, seed, o.UserAgent...)
+	p := &Profile{Headers: ua.Headers}
+
+	if t, ok := ua.Token(useragent.START_PLATFORM, useragent.END_PLATFORM); ok {
+		p.Platform = t.String()
+	}
+	for _, r := range platformVersions {
+		if t, ok := ua.Token(r[0], r[1]); ok {
+			p.PlatformVersion = t.String()
+		}
+	}
+	if t, ok := ua.Token(useragent.START_ARCH, useragent.END_ARCH); ok {
+		p.Arch = t.String()
+	}
+	if t, ok := ua.Token(useragent.START_ANDROID_MODEL, useragent.END_ANDROID_MODEL); ok {
+		p.Model = t.String()
+	}
+	if t, ok := ua.Token(useragent.START_CHROME, useragent.END_CHROME); ok {
+		p.Browser, p.BrowserVersion = "Chrome", strings.TrimPrefix(t.String(), "Chrome/")
+	}
+	if t, ok := ua.Token(useragent.START_SAFARI, useragent.END_SAFARI); ok {
+		p.Browser, p.BrowserVersion = "Safari", strings.TrimPrefix(t.String(), "Version/")
+	}
+
+	engine, engineVersion := p.Engine()
+
+	var err error
+	webglOpts := append([]webgl.Option{webgl.WithArch(p.Arch), webgl.WithBrowser(engine, engineVersion)}, o.WebGL...)
+	p.WebGL, err = webgl.GenerateProfile(seed, p.Platform, p.PlatformVersion, webglOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Canvas, err = canvas.GenerateProfile(seed, p.Platform, canvas.WithGPU(p.WebGL.GPU))
+	if err != nil {
+		return nil, err
+	}
+
+	p.Audio, err = audio.GenerateProfile(seed, p.Platform, audio.WithBrowser(engine), audio.WithArch(p.Arch))
+	if err != nil {
+		return nil, err
+	}
+
+	p.Screen, err = screen.GenerateProfile(seed, p.Platform, p.PlatformVersion,
+		screen.WithArch(p.Arch), screen.WithBrowser(p.Browser), screen.WithModel(p.Model))
+	if err != nil {
+		return nil, err
+	}
+
+	localeOpts := []locale.Option{locale.WithBrowser(engine)}
+	if o.Country != "" {
+		localeOpts = append(localeOpts, locale.WithCountry(o.Country))
+	}
+	p.Locale, err = locale.GenerateProfile(seed, localeOpts...)
+	if err != nil {
+		return nil, err
+	}
+	p.Headers["accept-language"] = p.Locale.AcceptLanguage
+
+	p.Headers["accept-encoding"], err = fetch.AcceptEncoding(engine, engineVersion, true)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Navigator, err = navigator.GenerateProfile(seed, ua,
+		navigator.WithGPU(p.WebGL.GPU), navigator.WithLanguages(p.Locale.Languages...))
+	if err != nil {
+		return nil, err
+	}
+
+	p.Fonts, err = fonts.GenerateProfile(seed, p.Platform, p.PlatformVersion, fonts.WithBrowser(engine))
+	if err != nil {
+		return nil, err
+	}
+
+	p.TLS, err = tls.GenerateClientHello(seed, engine, engineVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	p.HTTP2, err = http2.GenerateProfile(engine, engineVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Cookies, err = cookiejar.GeneratePolicy(engine, engineVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Engine returns the browser and version the network and rendering behaviour of the profile
+// follows. Every browser on iOS and iPadOS renders with the WebKit of the platform version.
+func (p *Profile) Engine() (string, string) {
+	if p.Platform == "iOS" || p.Platform == "iPadOS" {
+		return "Safari", p.PlatformVersion
+	}
+	return p.Browser, p.BrowserVersion
+}
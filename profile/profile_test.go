@@ -0,0 +1,135 @@
+package profile
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/chinese-room-solutions/fakebro/cookiejar"
+	"github.com/chinese-room-solutions/fakebro/useragent"
+	"github.com/chinese-room-solutions/fakebro/webgl"
+	"github.com/stretchr/testify/require"
+)
+
+func onlyPlatform(platform useragent.TokenType) Option {
+	return WithUserAgent(useragent.WithCondition(func(tt useragent.TokenType) bool {
+		return !useragent.In(tt, useragent.START_PLATFORM, useragent.END_PLATFORM) || tt == platform
+	}))
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		platform string
+		check    func(t *testing.T, p *Profile)
+	}{
+		{
+			name:     "Windows",
+			opts:     []Option{onlyPlatform(useragent.PLATFORM_WINDOWS)},
+			platform: "Windows",
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, "Chrome", p.Browser)
+				require.Regexp(t, `^ANGLE \(`, p.WebGL.UnmaskedRenderer)
+				require.Equal(t, "Win32", p.Navigator.Platform)
+				require.Equal(t, "Google Inc.", p.Navigator.Vendor)
+				require.Contains(t, p.Fonts.Fonts, "Segoe UI")
+				require.Equal(t, "chrome", p.TLS.Browser)
+				require.Equal(t, "1:65536;2:0;4:6291456;6:262144|15663105|0|m,a,s,p", p.HTTP2.Akamai())
+				require.True(t, p.Cookies.LaxByDefault)
+				require.Less(t, p.Screen.AvailHeight, p.Screen.Height)
+			},
+		},
+		{
+			name:     "macOS",
+			opts:     []Option{onlyPlatform(useragent.PLATFORM_MACOS)},
+			platform: "macOS",
+			check: func(t *testing.T, p *Profile) {
+				if p.Browser == "Safari" {
+					require.Equal(t, "Apple GPU", p.WebGL.UnmaskedRenderer)
+					require.InDelta(t, 35.1, p.Audio.SampleSum, 0.1)
+				} else {
+					require.Contains(t, p.WebGL.UnmaskedRenderer, "Apple M")
+					require.InDelta(t, 124.0, p.Audio.SampleSum, 0.1)
+				}
+				require.Equal(t, 25, p.Screen.AvailTop)
+			},
+		},
+		{
+			name:     "Android",
+			opts:     []Option{onlyPlatform(useragent.PLATFORM_ANDROID)},
+			platform: "Android",
+			check: func(t *testing.T, p *Profile) {
+				require.NotEmpty(t, p.Model)
+				require.Equal(t, p.Screen.Width, p.Screen.InnerWidth)
+				require.Equal(t, 5, p.Navigator.MaxTouchPoints)
+				require.Greater(t, p.Screen.DevicePixelRatio, 2.0)
+			},
+		},
+		{
+			name:     "iOS",
+			opts:     []Option{onlyPlatform(useragent.PLATFORM_IOS)},
+			platform: "iOS",
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, "Apple GPU", p.WebGL.UnmaskedRenderer)
+				require.Equal(t, "WebKit", p.WebGL.Vendor)
+				require.Equal(t, "iPhone", p.Navigator.Platform)
+				require.Equal(t, "safari", p.TLS.Browser)
+				engine, engineVersion := p.Engine()
+				require.Equal(t, "Safari", engine)
+				require.Equal(t, p.PlatformVersion, engineVersion)
+				require.Equal(t, "gzip, deflate, br", p.Headers["accept-encoding"])
+				require.Equal(t, cookiejar.ThirdPartyBlock, p.Cookies.ThirdParty)
+				require.Equal(t, "Apple Computer, Inc.", p.Navigator.Vendor)
+				require.Greater(t, p.Screen.DevicePixelRatio, 1.0)
+			},
+		},
+		{
+			name:     "country",
+			opts:     []Option{onlyPlatform(useragent.PLATFORM_WINDOWS), WithCountry("DE")},
+			platform: "Windows",
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, "Europe/Berlin", p.Locale.TimeZone)
+				require.Regexp(t, `^de`, p.Headers["accept-language"])
+				require.Equal(t, p.Locale.Languages, p.Navigator.Languages)
+			},
+		},
+		{
+			name: "without virtual GPUs",
+			opts: []Option{
+				onlyPlatform(useragent.PLATFORM_LINUX),
+				WithWebGL(webgl.WithoutRendererMatch(regexp.MustCompile(`llvmpipe`))),
+			},
+			platform: "Linux",
+			check: func(t *testing.T, p *Profile) {
+				require.NotContains(t, p.WebGL.UnmaskedRenderer, "llvmpipe")
+				require.True(t, p.Canvas.Accelerated)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 30; seed++ {
+				p, err := Generate(seed, tt.opts...)
+				require.NoError(t, err)
+				require.Equal(t, tt.platform, p.Platform)
+				require.NotEmpty(t, p.PlatformVersion)
+				require.NotEmpty(t, p.BrowserVersion)
+				require.Len(t, p.Canvas.PixelHashes, 3)
+				tt.check(t, p)
+			}
+		})
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		p, err := Generate(seed)
+		require.NoError(t, err)
+		require.NotEmpty(t, p.Headers["user-agent"])
+
+		again, err := Generate(seed)
+		require.NoError(t, err)
+		require.Equal(t, p, again)
+	}
+}
@@ -0,0 +1,264 @@
+package screen
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/chinese-room-solutions/fakebro/internal/random"
+	"github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedPlatform    = fmt.Errorf("unsupported platform")
+	ErrInvalidPlatformVersion = fmt.Errorf("invalid platform version")
+)
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Profile holds the screen and window values a page observes, in CSS pixels.
+type Profile struct {
+	Width            int
+	Height           int
+	AvailWidth       int
+	AvailHeight      int
+	AvailLeft        int
+	AvailTop         int
+	ColorDepth       int
+	PixelDepth       int
+	DevicePixelRatio float64
+	OuterWidth       int
+	OuterHeight      int
+	InnerWidth       int
+	InnerHeight      int
+	ScreenX          int
+	ScreenY          int
+}
+
+type Screen struct {
+	Width  int      `yaml:"width"`
+	Height int      `yaml:"height"`
+	DPR    float64  `yaml:"dpr"`
+	Arch   string   `yaml:"arch"`
+	Models []string `yaml:"models"`
+	Weight int      `yaml:"weight"`
+}
+
+type Inset struct {
+	Top      int    `yaml:"top"`
+	Bottom   int    `yaml:"bottom"`
+	Left     int    `yaml:"left"`
+	Right    int    `yaml:"right"`
+	Versions string `yaml:"versions"`
+	Weight   int    `yaml:"weight"`
+
+	versions version.Constraints
+}
+
+type Toolbar struct {
+	Top    int `yaml:"top"`
+	Bottom int `yaml:"bottom"`
+	Weight int `yaml:"weight"`
+}
+
+type ColorDepth struct {
+	Value  int `yaml:"value"`
+	Weight int `yaml:"weight"`
+}
+
+type PlatformData struct {
+	Screens     []Screen             `yaml:"screens"`
+	Insets      []Inset              `yaml:"insets"`
+	ColorDepths []ColorDepth         `yaml:"color_depths"`
+	Frame       int                  `yaml:"frame"`
+	Toolbars    map[string][]Toolbar `yaml:"toolbars"`
+	Maximized   float64              `yaml:"maximized"`
+	Mobile      bool                 `yaml:"mobile"`
+}
+
+var data map[string]*PlatformData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for platform, p := range data {
+		if len(p.Screens) == 0 || len(p.ColorDepths) == 0 {
+			panic(fmt.Sprintf("Incomplete screen data for %s", platform))
+		}
+		for i := range p.Insets {
+			if p.Insets[i].Versions == "" {
+				continue
+			}
+			p.Insets[i].versions, err = version.NewConstraint(p.Insets[i].Versions)
+			if err != nil {
+				panic(fmt.Sprintf("Invalid inset versions for %s: %v", platform, err))
+			}
+		}
+	}
+}
+
+type options struct {
+	Arch    string
+	Browser string
+	Model   string
+}
+
+type Option func(*options)
+
+// WithArch selects screens for the given sec-ch-ua-arch value, e.g. Retina displays for "arm" Macs.
+func WithArch(arch string) Option {
+	return func(o *options) {
+		o.Arch = arch
+	}
+}
+
+// WithBrowser sets the browser whose toolbars take the window space: "Chrome", "Firefox" or "Safari".
+// Other browsers are treated as Chromium. Without it, Chrome is assumed.
+func WithBrowser(name string) Option {
+	return func(o *options) {
+		o.Browser = name
+	}
+}
+
+// WithModel selects the screen of the given sec-ch-ua-model device, when known.
+func WithModel(model string) Option {
+	return func(o *options) {
+		o.Model = model
+	}
+}
+
+func (s *Screen) matches(o options) bool {
+	switch strings.ToLower(s.Arch) {
+	case "":
+		return true
+	case "arm":
+		return o.Arch == "" || strings.EqualFold(o.Arch, "arm")
+	default:
+		return o.Arch == "" || !strings.EqualFold(o.Arch, "arm")
+	}
+}
+
+// screensFor returns the screens of the device model if the catalog knows it,
+// and the screens of unlisted devices otherwise.
+func (p *PlatformData) screensFor(o options) []Screen {
+	var device, generic []Screen
+	for _, s := range p.Screens {
+		if !s.matches(o) {
+			continue
+		}
+		switch {
+		case len(s.Models) == 0:
+			generic = append(generic, s)
+		case o.Model != "" && slices.Contains(s.Models, o.Model):
+			device = append(device, s)
+		}
+	}
+	if len(device) > 0 {
+		return device
+	}
+	return generic
+}
+
+func (p *PlatformData) insetsFor(platformVersion *version.Version) []Inset {
+	var insets []Inset
+	for _, inset := range p.Insets {
+		if platformVersion == nil || inset.versions == nil || inset.versions.Check(platformVersion) {
+			insets = append(insets, inset)
+		}
+	}
+	return insets
+}
+
+func (p *PlatformData) toolbarsFor(browser string) []Toolbar {
+	if toolbars, ok := p.Toolbars[browser]; ok {
+		return toolbars
+	}
+	return p.Toolbars["chrome"]
+}
+
+// GenerateProfile generates the screen and window values of a browser on the platform.
+// The same seed always produces the same values.
+func GenerateProfile(seed int64, platform string, platformVersion string, opts ...Option) (*Profile, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	browser := family.Of(o.Browser)
+
+	var p *PlatformData
+	for name, d := range data {
+		if strings.EqualFold(name, platform) || strings.EqualFold(platform, "chromeos") && name == "Chrome OS" {
+			p = d
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+	}
+
+	var v *version.Version
+	if platformVersion != "" {
+		var err error
+		v, err = version.NewVersion(platformVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlatformVersion, platformVersion)
+		}
+	}
+
+	screens := p.screensFor(o)
+	if len(screens) == 0 {
+		return nil, fmt.Errorf("%w: %s on %s", ErrUnsupportedPlatform, platform, o.Arch)
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	s := random.Pick(r, screens, func(s Screen) int { return s.Weight })
+	inset := random.Pick(r, p.insetsFor(v), func(i Inset) int { return i.Weight })
+	depth := random.Pick(r, p.ColorDepths, func(d ColorDepth) int { return d.Weight })
+	toolbar := random.Pick(r, p.toolbarsFor(browser), func(t Toolbar) int { return t.Weight })
+
+	profile := &Profile{
+		Width:            s.Width,
+		Height:           s.Height,
+		AvailLeft:        inset.Left,
+		AvailTop:         inset.Top,
+		AvailWidth:       s.Width - inset.Left - inset.Right,
+		AvailHeight:      s.Height - inset.Top - inset.Bottom,
+		ColorDepth:       depth.Value,
+		PixelDepth:       depth.Value,
+		DevicePixelRatio: s.DPR,
+	}
+
+	switch {
+	case p.Mobile:
+		profile.OuterWidth = profile.AvailWidth
+		profile.OuterHeight = profile.AvailHeight
+	case r.Float64() < p.Maximized:
+		// Maximized windows push their borders off the available area.
+		profile.OuterWidth = profile.AvailWidth + p.Frame
+		profile.OuterHeight = profile.AvailHeight + p.Frame
+		profile.ScreenX = profile.AvailLeft - p.Frame/2
+		profile.ScreenY = profile.AvailTop - p.Frame/2
+	default:
+		profile.OuterWidth = profile.AvailWidth * (60 + r.Intn(36)) / 100
+		profile.OuterHeight = profile.AvailHeight * (60 + r.Intn(36)) / 100
+		profile.ScreenX = profile.AvailLeft + r.Intn(profile.AvailWidth-profile.OuterWidth+1)
+		profile.ScreenY = profile.AvailTop + r.Intn(profile.AvailHeight-profile.OuterHeight+1)
+	}
+
+	profile.InnerWidth = profile.OuterWidth - p.Frame
+	profile.InnerHeight = profile.OuterHeight - p.Frame - toolbar.Top - toolbar.Bottom
+
+	return profile, nil
+}
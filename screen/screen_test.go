@@ -0,0 +1,135 @@
+package screen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name            string
+		platform        string
+		platformVersion string
+		opts            []Option
+		check           func(t *testing.T, p *Profile)
+		expectedError   error
+	}{
+		{
+			name:            "Windows 10 taskbar",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, p.Height-40, p.AvailHeight)
+				require.Equal(t, p.Width, p.AvailWidth)
+				require.Equal(t, p.OuterWidth-16, p.InnerWidth)
+			},
+		},
+		{
+			name:            "Windows 11 taskbar",
+			platform:        "Windows",
+			platformVersion: "14.0.0",
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, p.Height-48, p.AvailHeight)
+				if p.OuterWidth > p.AvailWidth {
+					require.Equal(t, p.AvailWidth+16, p.OuterWidth)
+					require.Equal(t, -8, p.ScreenX)
+					require.Equal(t, -8, p.ScreenY)
+				}
+			},
+		},
+		{
+			name:            "Apple silicon",
+			platform:        "macOS",
+			platformVersion: "14.6.1",
+			opts:            []Option{WithArch("arm"), WithBrowser("Safari")},
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, 25, p.AvailTop)
+				require.NotEqual(t, 1536, p.Width)
+				if p.Width != 1920 && p.Width != 2560 {
+					require.Equal(t, 2.0, p.DevicePixelRatio)
+				}
+				require.Contains(t, []int{24, 30}, p.ColorDepth)
+			},
+		},
+		{
+			name:            "Pixel 8 Pro",
+			platform:        "Android",
+			platformVersion: "14.0.0",
+			opts:            []Option{WithModel("Pixel 8 Pro")},
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, 448, p.Width)
+				require.Equal(t, 998, p.Height)
+				require.Equal(t, 3.0, p.DevicePixelRatio)
+				require.Equal(t, p.Width, p.InnerWidth)
+				require.Equal(t, p.Height-128, p.InnerHeight)
+			},
+		},
+		{
+			name:            "unknown Android model",
+			platform:        "Android",
+			platformVersion: "14.0.0",
+			opts:            []Option{WithModel("SM-G991B")},
+			check: func(t *testing.T, p *Profile) {
+				require.Contains(t, []int{412, 360, 393}, p.Width)
+			},
+		},
+		{
+			name:            "Safari on iPhone",
+			platform:        "iOS",
+			platformVersion: "18.0",
+			opts:            []Option{WithBrowser("Safari")},
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, p.Height, p.AvailHeight)
+				require.Equal(t, p.Height-180, p.InnerHeight)
+			},
+		},
+		{
+			name:            "ChromeOS alias",
+			platform:        "chromeos",
+			platformVersion: "15917.71.0",
+			check: func(t *testing.T, p *Profile) {
+				require.Equal(t, p.Height-48, p.AvailHeight)
+			},
+		},
+		{
+			name:            "invalid platform version",
+			platform:        "Windows",
+			platformVersion: "latest",
+			expectedError:   ErrInvalidPlatformVersion,
+		},
+		{
+			name:            "unsupported platform",
+			platform:        "PlayStation",
+			platformVersion: "11",
+			expectedError:   ErrUnsupportedPlatform,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				p, err := GenerateProfile(seed, tt.platform, tt.platformVersion, tt.opts...)
+				if tt.expectedError != nil {
+					require.ErrorIs(t, err, tt.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, p.ColorDepth, p.PixelDepth)
+				require.LessOrEqual(t, p.InnerWidth, p.OuterWidth)
+				require.Less(t, p.InnerHeight, p.OuterHeight)
+				require.LessOrEqual(t, p.AvailHeight, p.Height)
+				require.Positive(t, p.InnerHeight)
+				tt.check(t, p)
+			}
+		})
+	}
+}
+
+func TestGenerateProfileDeterministic(t *testing.T) {
+	p1, err := GenerateProfile(7, "Windows", "14.0.0")
+	require.NoError(t, err)
+	p2, err := GenerateProfile(7, "Windows", "14.0.0")
+	require.NoError(t, err)
+	require.Equal(t, p1, p2)
+}
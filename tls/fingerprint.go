@@ -0,0 +1,123 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JA3 returns the JA3 string of the hello: the version, cipher suites, extensions, supported groups
+// and point formats in decimal, without GREASE values.
+func (h *ClientHello) JA3() string {
+	join := func(values []uint16) string {
+		var s []string
+		for _, v := range values {
+			if !IsGREASE(v) {
+				s = append(s, strconv.Itoa(int(v)))
+			}
+		}
+		return strings.Join(s, "-")
+	}
+
+	formats := make([]uint16, len(h.ECPointFormats))
+	for i, f := range h.ECPointFormats {
+		formats[i] = uint16(f)
+	}
+	return fmt.Sprintf("%d,%s,%s,%s,%s", h.Version,
+		join(h.CipherSuites), join(h.Extensions), join(h.SupportedGroups), join(formats))
+}
+
+// JA3Hash returns the hex encoded MD5 of the JA3 string.
+func (h *ClientHello) JA3Hash() string {
+	sum := md5.Sum([]byte(h.JA3()))
+	return hex.EncodeToString(sum[:])
+}
+
+// JA4 returns the JA4 fingerprint of the hello as sent over TCP. Unlike JA3 it sorts the
+// cipher suites and extensions, so it does not change with the extension order.
+func (h *ClientHello) JA4() string {
+	ciphers := withoutGREASE(h.CipherSuites)
+	extensions := withoutGREASE(h.Extensions)
+
+	v := h.Version
+	for _, sv := range withoutGREASE(h.SupportedVersions) {
+		v = max(v, sv)
+	}
+	version := map[uint16]string{0x0304: "13", 0x0303: "12", 0x0302: "11", 0x0301: "10"}[v]
+	if version == "" {
+		version = "00"
+	}
+
+	sni := "i"
+	if slices.Contains(extensions, ExtensionServerName) {
+		sni = "d"
+	}
+
+	alpn := "00"
+	if len(h.ALPN) > 0 && h.ALPN[0] != "" {
+		first := h.ALPN[0]
+		alpn = first[:1] + first[len(first)-1:]
+	}
+
+	a := fmt.Sprintf("t%s%s%02d%02d%s", version, sni, min(len(ciphers), 99), min(len(extensions), 99), alpn)
+
+	slices.Sort(ciphers)
+	b := truncatedHash(hexList(ciphers))
+
+	extensions = slices.DeleteFunc(extensions, func(e uint16) bool {
+		return e == ExtensionServerName || e == ExtensionALPN
+	})
+	slices.Sort(extensions)
+	c := hexList(extensions)
+	if len(h.SignatureAlgorithms) > 0 {
+		c += "_" + hexList(h.SignatureAlgorithms)
+	}
+
+	return a + "_" + b + "_" + truncatedHash(c)
+}
+
+func withoutGREASE(values []uint16) []uint16 {
+	return slices.DeleteFunc(slices.Clone(values), IsGREASE)
+}
+
+func hexList(values []uint16) string {
+	var b bytes.Buffer
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%04x", v)
+	}
+	return b.String()
+}
+
+// truncatedHash returns the first 12 hex characters of the SHA-256 of s, or zeros if s is empty.
+func truncatedHash(s string) string {
+	if s == "" {
+		return "000000000000"
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// zeroReader reads zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
+}
+
+// Hello parses the ClientHello the spec produces for the server name, e.g. to compute its fingerprints.
+func (s *ClientHelloSpec) Hello(serverName string) (*ClientHello, error) {
+	b, err := s.Marshal(serverName, zeroReader{})
+	if err != nil {
+		return nil, err
+	}
+	return ParseClientHello(b)
+}
@@ -0,0 +1,123 @@
+package tls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprints(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+		expectedJA3    string
+		expectedJA4    []string
+	}{
+		{
+			browser:        "Chrome",
+			browserVersion: "120.0",
+			// Short hellos without a post-quantum key share are padded.
+			expectedJA4: []string{"t13d1516h2_8daaf6152771_02713d6af862", "t13d1517h2_8daaf6152771_b1ff8ab2d16f"},
+		},
+		{
+			browser:        "Chrome",
+			browserVersion: "129.0",
+			expectedJA4:    []string{"t13d1516h2_8daaf6152771_02713d6af862"},
+		},
+		{
+			browser:        "Safari",
+			browserVersion: "17.4",
+			expectedJA3:    "773906b0efdefa24a7f2b8eb6985bf37",
+			expectedJA4:    []string{"t13d2014h2_a09f3c656075_14788d8d241b"},
+		},
+		{
+			browser:        "Firefox",
+			browserVersion: "120.0",
+			expectedJA3:    "8bc88bfe0bd40b49be5cae8597feb9a2",
+			expectedJA4:    []string{"t13d1716h2_5b57614c22b0_eeeea6562960"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			ja3 := map[string]bool{}
+			for seed := int64(0); seed < 10; seed++ {
+				s, err := GenerateClientHello(seed, test.browser, test.browserVersion)
+				require.NoError(t, err)
+
+				h, err := s.Hello("example.com")
+				require.NoError(t, err)
+				require.Contains(t, test.expectedJA4, h.JA4())
+				if test.expectedJA3 != "" {
+					require.Equal(t, test.expectedJA3, h.JA3Hash())
+				}
+				ja3[h.JA3()] = true
+			}
+			// Chrome shuffles its extensions, so its JA3 changes with every connection.
+			require.Equal(t, test.expectedJA3 == "", len(ja3) > 1)
+		})
+	}
+}
+
+func TestJA4(t *testing.T) {
+	tests := []struct {
+		name     string
+		hello    ClientHello
+		expected string
+	}{
+		{
+			name: "no server name nor ALPN",
+			hello: ClientHello{
+				Version:             0x0303,
+				CipherSuites:        []uint16{0x1a1a, 0xc02f, 0x1301},
+				Extensions:          []uint16{0x1a1a, ExtensionSignatureAlgorithms, ExtensionSupportedVersions},
+				SignatureAlgorithms: []uint16{0x0403},
+				SupportedVersions:   []uint16{0x2a2a, 0x0304, 0x0303},
+			},
+			expected: "t13i0202" + "00_" + truncatedHash("1301,c02f") + "_" + truncatedHash("000d,002b_0403"),
+		},
+		{
+			name: "TLS 1.2 without signature algorithms",
+			hello: ClientHello{
+				Version:      0x0303,
+				CipherSuites: []uint16{0x002f},
+				Extensions:   []uint16{ExtensionServerName, ExtensionALPN},
+				ALPN:         []string{"http/1.1"},
+			},
+			expected: "t12d0102h1_" + truncatedHash("002f") + "_000000000000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.hello.JA4())
+		})
+	}
+}
+
+func TestParseClientHello(t *testing.T) {
+	s, err := GenerateClientHello(1, "Chrome", "129.0")
+	require.NoError(t, err)
+	b, err := s.Marshal("example.com", zeroReader{})
+	require.NoError(t, err)
+
+	h, err := ParseClientHello(b)
+	require.NoError(t, err)
+	require.Equal(t, "example.com", h.ServerName)
+	require.Equal(t, s.CipherSuites, h.CipherSuites)
+	require.Equal(t, s.Extensions[:len(s.Extensions)-1], h.Extensions)
+	require.Equal(t, s.SupportedGroups, h.SupportedGroups)
+	require.Equal(t, s.KeyShares, h.KeyShares)
+	require.Equal(t, s.SignatureAlgorithms, h.SignatureAlgorithms)
+	require.Equal(t, s.ALPN, h.ALPN)
+
+	// Without the record header.
+	handshake, err := ParseClientHello(b[5:])
+	require.NoError(t, err)
+	require.Equal(t, h, handshake)
+
+	for _, n := range []int{0, 5, 50, len(b) - 1} {
+		_, err = ParseClientHello(b[:n])
+		require.ErrorIs(t, err, ErrMalformedClientHello, "%d bytes", n)
+	}
+}
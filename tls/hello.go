@@ -0,0 +1,217 @@
+package tls
+
+import (
+	"fmt"
+	"io"
+	"net"
+)
+
+// keyShareLengths holds the length of the key exchange of the groups browsers send key shares for.
+var keyShareLengths = map[uint16]int{
+	GroupP256:                  65,
+	GroupP384:                  97,
+	GroupP521:                  133,
+	GroupX25519:                32,
+	GroupX25519MLKEM768:        1216,
+	GroupX25519Kyber768Draft00: 1216,
+}
+
+// builder appends the TLS presentation language encodings of values.
+type builder []byte
+
+func (b *builder) u8(v uint8) { *b = append(*b, v) }
+
+func (b *builder) u16(v uint16) { *b = append(*b, byte(v>>8), byte(v)) }
+
+func (b *builder) bytes(v []byte) { *b = append(*b, v...) }
+
+// prefixed appends the value written by f, preceded by its length on n bytes.
+func (b *builder) prefixed(n int, f func(*builder)) {
+	start := len(*b)
+	*b = append(*b, make([]byte, n)...)
+	f(b)
+	length := len(*b) - start - n
+	for i := range n {
+		(*b)[start+i] = byte(length >> (8 * (n - 1 - i)))
+	}
+}
+
+func (b *builder) u16s(n int, values []uint16) {
+	b.prefixed(n, func(b *builder) {
+		for _, v := range values {
+			b.u16(v)
+		}
+	})
+}
+
+func (b *builder) protocols(values []string) {
+	b.prefixed(2, func(b *builder) {
+		for _, v := range values {
+			b.prefixed(1, func(b *builder) { b.bytes([]byte(v)) })
+		}
+	})
+}
+
+// Marshal returns the TLS record of the ClientHello for the server name, with the random
+// values read from random. IP addresses are not sent as server name. The record length
+// only depends on the spec and the server name, so does its fingerprint.
+func (s *ClientHelloSpec) Marshal(serverName string, random io.Reader) ([]byte, error) {
+	if net.ParseIP(serverName) != nil {
+		serverName = ""
+	}
+	read := func(n int) ([]byte, error) {
+		v := make([]byte, n)
+		if _, err := io.ReadFull(random, v); err != nil {
+			return nil, fmt.Errorf("failed to read random: %w", err)
+		}
+		return v, nil
+	}
+
+	clientRandom, err := read(32)
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := read(32)
+	if err != nil {
+		return nil, err
+	}
+
+	type extension struct {
+		typ  uint16
+		data []byte
+	}
+	var extensions []extension
+	greaseSeen := false
+	for _, e := range s.Extensions {
+		var data []byte
+		switch {
+		case e == ExtensionServerName && serverName == "":
+			continue
+		case IsGREASE(e):
+			// The second GREASE extension carries a single zero byte.
+			if greaseSeen {
+				data = []byte{0}
+			}
+			greaseSeen = true
+		case e != ExtensionPadding:
+			data, err = s.extension(e, serverName, read)
+			if err != nil {
+				return nil, err
+			}
+		}
+		extensions = append(extensions, extension{typ: e, data: data})
+	}
+
+	var body builder
+	body.u16(VersionTLS12)
+	body.bytes(clientRandom)
+	body.prefixed(1, func(b *builder) { b.bytes(sessionID) })
+	body.u16s(2, s.CipherSuites)
+	body.prefixed(1, func(b *builder) { b.u8(0) })
+
+	// BoringSSL and NSS pad hellos between 256 and 511 bytes to 512 bytes,
+	// as some middleboxes hang on hellos of that size.
+	length := 4 + len(body) + 2
+	for _, e := range extensions {
+		if e.typ != ExtensionPadding {
+			length += 4 + len(e.data)
+		}
+	}
+	padding := -1
+	if length > 0xff && length < 0x200 {
+		padding = max(0x200-length-4, 1)
+	}
+
+	body.prefixed(2, func(b *builder) {
+		for _, e := range extensions {
+			if e.typ == ExtensionPadding {
+				if padding < 0 {
+					continue
+				}
+				e.data = make([]byte, padding)
+			}
+			b.u16(e.typ)
+			b.prefixed(2, func(b *builder) { b.bytes(e.data) })
+		}
+	})
+
+	var record builder
+	record.u8(0x16)
+	record.u16(0x0301)
+	record.prefixed(2, func(b *builder) {
+		b.u8(0x01)
+		b.prefixed(3, func(b *builder) { b.bytes(body) })
+	})
+	return record, nil
+}
+
+// extension returns the data of the extension e.
+func (s *ClientHelloSpec) extension(e uint16, serverName string, read func(int) ([]byte, error)) ([]byte, error) {
+	var b builder
+	switch e {
+	case ExtensionServerName:
+		b.prefixed(2, func(b *builder) {
+			b.u8(0)
+			b.prefixed(2, func(b *builder) { b.bytes([]byte(serverName)) })
+		})
+	case ExtensionStatusRequest:
+		b.bytes([]byte{1, 0, 0, 0, 0})
+	case ExtensionSupportedGroups:
+		b.u16s(2, s.SupportedGroups)
+	case ExtensionECPointFormats:
+		b.prefixed(1, func(b *builder) { b.bytes(s.ECPointFormats) })
+	case ExtensionSignatureAlgorithms:
+		b.u16s(2, s.SignatureAlgorithms)
+	case ExtensionALPN:
+		b.protocols(s.ALPN)
+	case ExtensionCompressCertificate:
+		b.u16s(1, s.CertCompression)
+	case ExtensionRecordSizeLimit:
+		b.u16(s.RecordSizeLimit)
+	case ExtensionDelegatedCredentials:
+		b.u16s(2, s.DelegatedCredentials)
+	case ExtensionSupportedVersions:
+		b.u16s(1, s.SupportedVersions)
+	case ExtensionPSKKeyExchangeModes:
+		b.prefixed(1, func(b *builder) { b.bytes(s.PSKModes) })
+	case ExtensionRenegotiationInfo:
+		b.u8(0)
+	case ExtensionApplicationSettings:
+		b.protocols(s.ALPS)
+	case ExtensionKeyShare:
+		var shares builder
+		for _, group := range s.KeyShares {
+			n, ok := keyShareLengths[group]
+			if IsGREASE(group) {
+				n, ok = 1, true
+			}
+			if !ok {
+				return nil, fmt.Errorf("no key share length for group %#04x", group)
+			}
+			key, err := read(n)
+			if err != nil {
+				return nil, err
+			}
+			if IsGREASE(group) {
+				key[0] = 0
+			}
+			shares.u16(group)
+			shares.prefixed(2, func(b *builder) { b.bytes(key) })
+		}
+		b.prefixed(2, func(b *builder) { b.bytes(shares) })
+	case ExtensionEncryptedClientHello:
+		// A GREASE outer ECH: HKDF-SHA256, AES-128-GCM, a random config id,
+		// a random X25519 encapsulated key and a random payload.
+		enc, err := read(33 + s.ECHPayloadLength)
+		if err != nil {
+			return nil, err
+		}
+		b.u8(0)
+		b.u16(0x0001)
+		b.u16(0x0001)
+		b.u8(enc[0])
+		b.prefixed(2, func(b *builder) { b.bytes(enc[1:33]) })
+		b.prefixed(2, func(b *builder) { b.bytes(enc[33:]) })
+	}
+	return b, nil
+}
@@ -0,0 +1,97 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/rand"
+	stdtls "crypto/tls"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serverHello feeds the hello to a crypto/tls server and returns what the server understood of it.
+func serverHello(t *testing.T, hello []byte) *stdtls.ClientHelloInfo {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	errStop := errors.New("stop")
+	infos := make(chan *stdtls.ClientHelloInfo, 1)
+	go func() {
+		defer server.Close()
+		conn := stdtls.Server(server, &stdtls.Config{
+			GetConfigForClient: func(info *stdtls.ClientHelloInfo) (*stdtls.Config, error) {
+				infos <- info
+				return nil, errStop
+			},
+		})
+		err := conn.Handshake()
+		if !errors.Is(err, errStop) {
+			close(infos)
+		}
+	}()
+
+	go func() {
+		_, _ = client.Write(hello)
+		_, _ = client.Read(make([]byte, 1024))
+	}()
+
+	info, ok := <-infos
+	require.True(t, ok, "crypto/tls rejected the hello")
+	return info
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		browser        string
+		browserVersion string
+		serverName     string
+		expectedLength int
+	}{
+		{browser: "Chrome", browserVersion: "120.0", serverName: "example.com"},
+		{browser: "Chrome", browserVersion: "129.0", serverName: "example.com"},
+		{browser: "Chrome", browserVersion: "131.0", serverName: "127.0.0.1"},
+		{browser: "Firefox", browserVersion: "133.0", serverName: "example.com"},
+		{browser: "Safari", browserVersion: "17.4", serverName: "example.com", expectedLength: 517},
+		{browser: "Safari", browserVersion: "18.0", serverName: "www.example.com", expectedLength: 517},
+	}
+
+	for _, test := range tests {
+		t.Run(test.browser+" "+test.browserVersion, func(t *testing.T) {
+			s, err := GenerateClientHello(1, test.browser, test.browserVersion)
+			require.NoError(t, err)
+
+			b, err := s.Marshal(test.serverName, rand.Reader)
+			require.NoError(t, err)
+			if test.expectedLength > 0 {
+				require.Len(t, b, test.expectedLength)
+			}
+
+			info := serverHello(t, b)
+			require.Equal(t, s.CipherSuites, info.CipherSuites)
+			require.Equal(t, s.SupportedVersions, info.SupportedVersions)
+			require.Equal(t, s.ALPN, info.SupportedProtos)
+			require.Equal(t, s.ECPointFormats, info.SupportedPoints)
+			if net.ParseIP(test.serverName) == nil {
+				require.Equal(t, test.serverName, info.ServerName)
+			} else {
+				require.Empty(t, info.ServerName)
+			}
+
+			// The random values do not change the layout of the hello.
+			again, err := s.Marshal(test.serverName, zeroReader{})
+			require.NoError(t, err)
+			require.Len(t, again, len(b))
+			require.False(t, bytes.Equal(b, again))
+		})
+	}
+}
+
+func TestMarshalShortRandom(t *testing.T) {
+	s, err := GenerateClientHello(1, "Chrome", "129.0")
+	require.NoError(t, err)
+
+	_, err = s.Marshal("example.com", bytes.NewReader(make([]byte, 40)))
+	require.Error(t, err)
+}
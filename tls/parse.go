@@ -0,0 +1,128 @@
+package tls
+
+import (
+	"fmt"
+)
+
+var ErrMalformedClientHello = fmt.Errorf("malformed ClientHello")
+
+// ClientHello holds the fields of a ClientHello that fingerprints are computed from.
+type ClientHello struct {
+	Version             uint16
+	CipherSuites        []uint16
+	Extensions          []uint16
+	ServerName          string
+	SupportedGroups     []uint16
+	ECPointFormats      []uint8
+	SignatureAlgorithms []uint16
+	ALPN                []string
+	SupportedVersions   []uint16
+	KeyShares           []uint16
+}
+
+// reader consumes the TLS presentation language encodings of values.
+type reader struct {
+	b   []byte
+	err bool
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err || n > len(r.b) {
+		r.err = true
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) uint(n int) int {
+	v := 0
+	for _, c := range r.take(n) {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+func (r *reader) u8() uint8 { return uint8(r.uint(1)) }
+
+func (r *reader) u16() uint16 { return uint16(r.uint(2)) }
+
+// prefixed returns a reader on the value preceded by its length on n bytes.
+func (r *reader) prefixed(n int) *reader {
+	v := &reader{b: r.take(r.uint(n))}
+	v.err = r.err
+	return v
+}
+
+func (r *reader) u16s() []uint16 {
+	var values []uint16
+	for len(r.b) > 0 && !r.err {
+		values = append(values, r.u16())
+	}
+	return values
+}
+
+// ParseClientHello parses a ClientHello handshake message, with or without its TLS record header.
+func ParseClientHello(b []byte) (*ClientHello, error) {
+	r := &reader{b: b}
+	if len(b) > 0 && b[0] == 0x16 {
+		r.take(3)
+		r = r.prefixed(2)
+	}
+	if r.u8() != 0x01 {
+		return nil, fmt.Errorf("%w: not a ClientHello", ErrMalformedClientHello)
+	}
+	r = r.prefixed(3)
+
+	h := &ClientHello{Version: r.u16()}
+	r.take(32)
+	r.prefixed(1)
+	h.CipherSuites = r.prefixed(2).u16s()
+	r.prefixed(1)
+
+	extensions := r.prefixed(2)
+	for len(extensions.b) > 0 && !extensions.err {
+		e := extensions.u16()
+		ext := extensions.prefixed(2)
+		h.Extensions = append(h.Extensions, e)
+
+		switch e {
+		case ExtensionServerName:
+			names := ext.prefixed(2)
+			if names.u8() == 0 {
+				h.ServerName = string(names.prefixed(2).b)
+			}
+			ext.err = ext.err || names.err
+		case ExtensionSupportedGroups:
+			h.SupportedGroups = ext.prefixed(2).u16s()
+		case ExtensionECPointFormats:
+			h.ECPointFormats = ext.prefixed(1).b
+		case ExtensionSignatureAlgorithms:
+			h.SignatureAlgorithms = ext.prefixed(2).u16s()
+		case ExtensionALPN:
+			protocols := ext.prefixed(2)
+			for len(protocols.b) > 0 && !protocols.err {
+				h.ALPN = append(h.ALPN, string(protocols.prefixed(1).b))
+			}
+			ext.err = ext.err || protocols.err
+		case ExtensionSupportedVersions:
+			h.SupportedVersions = ext.prefixed(1).u16s()
+		case ExtensionKeyShare:
+			shares := ext.prefixed(2)
+			for len(shares.b) > 0 && !shares.err {
+				h.KeyShares = append(h.KeyShares, shares.u16())
+				shares.prefixed(2)
+			}
+			ext.err = ext.err || shares.err
+		}
+		if ext.err {
+			return nil, fmt.Errorf("%w: extension %d", ErrMalformedClientHello, e)
+		}
+	}
+
+	if r.err || extensions.err {
+		return nil, fmt.Errorf("%w: truncated", ErrMalformedClientHello)
+	}
+	return h, nil
+}
@@ -0,0 +1,329 @@
+package tls
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+	"github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedBrowser    = fmt.Errorf("unsupported browser")
+	ErrInvalidBrowserVersion = fmt.Errorf("invalid browser version")
+)
+
+//go:embed data.yml
+var dataFile embed.FS
+
+// Extension types.
+const (
+	ExtensionServerName                 uint16 = 0
+	ExtensionStatusRequest              uint16 = 5
+	ExtensionSupportedGroups            uint16 = 10
+	ExtensionECPointFormats             uint16 = 11
+	ExtensionSignatureAlgorithms        uint16 = 13
+	ExtensionALPN                       uint16 = 16
+	ExtensionSignedCertificateTimestamp uint16 = 18
+	ExtensionPadding                    uint16 = 21
+	ExtensionExtendedMasterSecret       uint16 = 23
+	ExtensionCompressCertificate        uint16 = 27
+	ExtensionRecordSizeLimit            uint16 = 28
+	ExtensionDelegatedCredentials       uint16 = 34
+	ExtensionSessionTicket              uint16 = 35
+	ExtensionSupportedVersions          uint16 = 43
+	ExtensionPSKKeyExchangeModes        uint16 = 45
+	ExtensionKeyShare                   uint16 = 51
+	ExtensionApplicationSettings        uint16 = 17513
+	ExtensionEncryptedClientHello       uint16 = 65037
+	ExtensionRenegotiationInfo          uint16 = 65281
+)
+
+// Named groups.
+const (
+	GroupP256                  uint16 = 23
+	GroupP384                  uint16 = 24
+	GroupP521                  uint16 = 25
+	GroupX25519                uint16 = 29
+	GroupFFDHE2048             uint16 = 256
+	GroupFFDHE3072             uint16 = 257
+	GroupX25519MLKEM768        uint16 = 0x11ec
+	GroupX25519Kyber768Draft00 uint16 = 0x6399
+)
+
+// Certificate compression algorithms.
+const (
+	CertCompressionZlib   uint16 = 1
+	CertCompressionBrotli uint16 = 2
+	CertCompressionZstd   uint16 = 3
+)
+
+const (
+	VersionTLS12 uint16 = 0x0303
+	VersionTLS13 uint16 = 0x0304
+
+	// greasePlaceholder marks the GREASE positions of the catalog specs.
+	greasePlaceholder uint16 = 0x0a0a
+)
+
+var extensionNames = map[string]uint16{
+	"server_name":                            ExtensionServerName,
+	"status_request":                         ExtensionStatusRequest,
+	"supported_groups":                       ExtensionSupportedGroups,
+	"ec_point_formats":                       ExtensionECPointFormats,
+	"signature_algorithms":                   ExtensionSignatureAlgorithms,
+	"application_layer_protocol_negotiation": ExtensionALPN,
+	"signed_certificate_timestamp":           ExtensionSignedCertificateTimestamp,
+	"padding":                                ExtensionPadding,
+	"extended_master_secret":                 ExtensionExtendedMasterSecret,
+	"compress_certificate":                   ExtensionCompressCertificate,
+	"record_size_limit":                      ExtensionRecordSizeLimit,
+	"delegated_credentials":                  ExtensionDelegatedCredentials,
+	"session_ticket":                         ExtensionSessionTicket,
+	"supported_versions":                     ExtensionSupportedVersions,
+	"psk_key_exchange_modes":                 ExtensionPSKKeyExchangeModes,
+	"key_share":                              ExtensionKeyShare,
+	"application_settings":                   ExtensionApplicationSettings,
+	"encrypted_client_hello":                 ExtensionEncryptedClientHello,
+	"renegotiation_info":                     ExtensionRenegotiationInfo,
+}
+
+var groupNames = map[string]uint16{
+	"P-256":                 GroupP256,
+	"P-384":                 GroupP384,
+	"P-521":                 GroupP521,
+	"X25519":                GroupX25519,
+	"ffdhe2048":             GroupFFDHE2048,
+	"ffdhe3072":             GroupFFDHE3072,
+	"X25519MLKEM768":        GroupX25519MLKEM768,
+	"X25519Kyber768Draft00": GroupX25519Kyber768Draft00,
+}
+
+var certCompressionNames = map[string]uint16{
+	"zlib":   CertCompressionZlib,
+	"brotli": CertCompressionBrotli,
+	"zstd":   CertCompressionZstd,
+}
+
+// IsGREASE reports whether v is one of the GREASE values of RFC 8701.
+func IsGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// ClientHelloSpec describes the ClientHello a browser sends. GREASE positions hold the
+// values chosen for the connection.
+type ClientHelloSpec struct {
+	Browser              string
+	CipherSuites         []uint16
+	Extensions           []uint16
+	SupportedGroups      []uint16
+	KeyShares            []uint16
+	SignatureAlgorithms  []uint16
+	DelegatedCredentials []uint16
+	SupportedVersions    []uint16
+	ALPN                 []string
+	// ALPS lists the protocols sent in the application_settings extension.
+	ALPS            []string
+	CertCompression []uint16
+	PSKModes        []uint8
+	ECPointFormats  []uint8
+	RecordSizeLimit uint16
+	// ECHPayloadLength is the length of the GREASE encrypted_client_hello payload.
+	ECHPayloadLength int
+	// ShuffleExtensions tells whether the browser permutes the extensions of every ClientHello,
+	// so that connections should not reuse the order of Extensions.
+	ShuffleExtensions bool
+}
+
+type helloProfile struct {
+	Browser              string   `yaml:"browser"`
+	Versions             string   `yaml:"versions"`
+	ShuffleExtensions    bool     `yaml:"shuffle_extensions"`
+	CipherSuites         []string `yaml:"cipher_suites"`
+	Extensions           []string `yaml:"extensions"`
+	SupportedGroups      []string `yaml:"supported_groups"`
+	KeyShares            []string `yaml:"key_shares"`
+	SignatureAlgorithms  []string `yaml:"signature_algorithms"`
+	DelegatedCredentials []string `yaml:"delegated_credentials"`
+	SupportedVersions    []string `yaml:"supported_versions"`
+	ALPN                 []string `yaml:"alpn"`
+	ALPS                 []string `yaml:"alps"`
+	CertCompression      []string `yaml:"cert_compression"`
+	PSKModes             []uint8  `yaml:"psk_modes"`
+	ECPointFormats       []uint8  `yaml:"ec_point_formats"`
+	RecordSizeLimit      uint16   `yaml:"record_size_limit"`
+
+	versions version.Constraints
+	spec     ClientHelloSpec
+}
+
+type helloData struct {
+	Profiles []helloProfile `yaml:"profiles"`
+}
+
+var data helloData
+
+func init() {
+	yamlData, err := dataFile.ReadFile("data.yml")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
+	}
+
+	err = yaml.Unmarshal(yamlData, &data)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+	}
+
+	for i := range data.Profiles {
+		p := &data.Profiles[i]
+		p.versions, err = version.NewConstraint(p.Versions)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid versions for %s: %v", p.Browser, err))
+		}
+		p.spec, err = p.compile()
+		if err != nil {
+			panic(fmt.Sprintf("Invalid ClientHello for %s %s: %v", p.Browser, p.Versions, err))
+		}
+	}
+}
+
+// compile converts the catalog names and numbers to code points.
+func (p *helloProfile) compile() (ClientHelloSpec, error) {
+	s := ClientHelloSpec{
+		Browser:         p.Browser,
+		ALPN:            p.ALPN,
+		ALPS:            p.ALPS,
+		PSKModes:        p.PSKModes,
+		ECPointFormats:  p.ECPointFormats,
+		RecordSizeLimit: p.RecordSizeLimit,
+	}
+	lists := []struct {
+		dst   *[]uint16
+		src   []string
+		names map[string]uint16
+	}{
+		{&s.CipherSuites, p.CipherSuites, nil},
+		{&s.Extensions, p.Extensions, extensionNames},
+		{&s.SupportedGroups, p.SupportedGroups, groupNames},
+		{&s.KeyShares, p.KeyShares, groupNames},
+		{&s.SignatureAlgorithms, p.SignatureAlgorithms, nil},
+		{&s.DelegatedCredentials, p.DelegatedCredentials, nil},
+		{&s.SupportedVersions, p.SupportedVersions, nil},
+		{&s.CertCompression, p.CertCompression, certCompressionNames},
+	}
+	for _, l := range lists {
+		for _, name := range l.src {
+			v, err := codePoint(name, l.names)
+			if err != nil {
+				return s, err
+			}
+			*l.dst = append(*l.dst, v)
+		}
+	}
+	if len(s.CipherSuites) == 0 || len(s.Extensions) == 0 {
+		return s, fmt.Errorf("no cipher suites or extensions")
+	}
+	return s, nil
+}
+
+func codePoint(name string, names map[string]uint16) (uint16, error) {
+	if name == "grease" {
+		return greasePlaceholder, nil
+	}
+	if v, ok := names[name]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(name, 0, 16)
+	if err != nil {
+		return 0, fmt.Errorf("unknown code point %q", name)
+	}
+	return uint16(v), nil
+}
+
+// GenerateClientHello returns the ClientHello of the browser version, e.g. "Chrome" and "129.0".
+// The seed picks the GREASE values and the extension order of browsers that shuffle them,
+// like a connection does.
+func GenerateClientHello(seed int64, browser, browserVersion string) (*ClientHelloSpec, error) {
+	browser, v, err := family.Parse(browser, browserVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBrowserVersion, browserVersion)
+	}
+
+	var p *helloProfile
+	for i := range data.Profiles {
+		if data.Profiles[i].Browser == browser && data.Profiles[i].versions.Check(v) {
+			p = &data.Profiles[i]
+			break
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedBrowser, browser, browserVersion)
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	s := p.spec.clone()
+
+	// BoringSSL picks independent GREASE values for each list and makes sure that the two
+	// GREASE extensions differ. The key share reuses the GREASE group.
+	cipher, group, version := grease(r), grease(r), grease(r)
+	ext1, ext2 := grease(r), grease(r)
+	if ext1 == ext2 {
+		ext2 ^= 0x1010
+	}
+	replaceGREASE(s.CipherSuites, cipher)
+	replaceGREASE(s.SupportedGroups, group)
+	replaceGREASE(s.KeyShares, group)
+	replaceGREASE(s.SupportedVersions, version)
+	replaceGREASE(s.Extensions, ext1, ext2)
+
+	if p.ShuffleExtensions {
+		shuffleExtensions(r, s.Extensions)
+	}
+	s.ShuffleExtensions = p.ShuffleExtensions
+	s.ECHPayloadLength = 144 + 32*r.Intn(4)
+
+	return &s, nil
+}
+
+func (s ClientHelloSpec) clone() ClientHelloSpec {
+	c := s
+	c.CipherSuites = append([]uint16{}, s.CipherSuites...)
+	c.Extensions = append([]uint16{}, s.Extensions...)
+	c.SupportedGroups = append([]uint16{}, s.SupportedGroups...)
+	c.KeyShares = append([]uint16{}, s.KeyShares...)
+	c.SupportedVersions = append([]uint16{}, s.SupportedVersions...)
+	return c
+}
+
+func grease(r *rand.Rand) uint16 {
+	n := uint16(r.Intn(16))
+	return n<<12 | 0x0a00 | n<<4 | 0x0a
+}
+
+// replaceGREASE replaces the GREASE placeholders in order with the values, repeating the last one.
+func replaceGREASE(values []uint16, grease ...uint16) {
+	i := 0
+	for j, v := range values {
+		if v == greasePlaceholder {
+			values[j] = grease[min(i, len(grease)-1)]
+			i++
+		}
+	}
+}
+
+// shuffleExtensions permutes the extensions like BoringSSL does, leaving GREASE and padding in place.
+func shuffleExtensions(r *rand.Rand, extensions []uint16) {
+	var positions []int
+	for i, e := range extensions {
+		if !IsGREASE(e) && e != ExtensionPadding {
+			positions = append(positions, i)
+		}
+	}
+	r.Shuffle(len(positions), func(i, j int) {
+		a, b := positions[i], positions[j]
+		extensions[a], extensions[b] = extensions[b], extensions[a]
+	})
+}
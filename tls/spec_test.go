@@ -0,0 +1,129 @@
+package tls
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateClientHello(t *testing.T) {
+	tests := []struct {
+		name              string
+		browser           string
+		browserVersion    string
+		expectedGroups    []uint16
+		expectedGREASE    bool
+		expectedShuffled  bool
+		expectedExtension uint16
+		expectedError     error
+	}{
+		{
+			name:              "Chrome 120",
+			browser:           "Chrome",
+			browserVersion:    "120.0",
+			expectedGroups:    []uint16{GroupX25519, GroupP256, GroupP384},
+			expectedGREASE:    true,
+			expectedShuffled:  true,
+			expectedExtension: ExtensionApplicationSettings,
+		},
+		{
+			name:              "Chrome 124 with Kyber",
+			browser:           "Chrome",
+			browserVersion:    "124.0.6367.60",
+			expectedGroups:    []uint16{GroupX25519Kyber768Draft00, GroupX25519, GroupP256, GroupP384},
+			expectedGREASE:    true,
+			expectedShuffled:  true,
+			expectedExtension: ExtensionEncryptedClientHello,
+		},
+		{
+			name:              "Edge 131 with ML-KEM",
+			browser:           "Edge",
+			browserVersion:    "131.0",
+			expectedGroups:    []uint16{GroupX25519MLKEM768, GroupX25519, GroupP256, GroupP384},
+			expectedGREASE:    true,
+			expectedShuffled:  true,
+			expectedExtension: ExtensionApplicationSettings,
+		},
+		{
+			name:              "Safari",
+			browser:           "Safari",
+			browserVersion:    "18.0",
+			expectedGroups:    []uint16{GroupX25519, GroupP256, GroupP384, GroupP521},
+			expectedGREASE:    true,
+			expectedExtension: ExtensionPadding,
+		},
+		{
+			name:              "Firefox",
+			browser:           "Firefox",
+			browserVersion:    "128.0",
+			expectedGroups:    []uint16{GroupX25519, GroupP256, GroupP384, GroupP521, GroupFFDHE2048, GroupFFDHE3072},
+			expectedExtension: ExtensionRecordSizeLimit,
+		},
+		{
+			name:           "unsupported version",
+			browser:        "Chrome",
+			browserVersion: "99.0",
+			expectedError:  ErrUnsupportedBrowser,
+		},
+		{
+			name:           "invalid version",
+			browser:        "Chrome",
+			browserVersion: "latest",
+			expectedError:  ErrInvalidBrowserVersion,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orders := map[string]bool{}
+			for seed := int64(0); seed < 20; seed++ {
+				s, err := GenerateClientHello(seed, test.browser, test.browserVersion)
+				if test.expectedError != nil {
+					require.ErrorIs(t, err, test.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, test.expectedGroups, withoutGREASE(s.SupportedGroups))
+				require.Contains(t, s.Extensions, test.expectedExtension)
+				require.Equal(t, test.expectedGREASE, IsGREASE(s.CipherSuites[0]))
+
+				if test.expectedGREASE {
+					first, last := s.Extensions[0], s.Extensions[len(s.Extensions)-2]
+					require.True(t, IsGREASE(first))
+					require.True(t, IsGREASE(last))
+					require.NotEqual(t, first, last)
+					require.Equal(t, s.SupportedGroups[0], s.KeyShares[0])
+				}
+
+				again, err := GenerateClientHello(seed, test.browser, test.browserVersion)
+				require.NoError(t, err)
+				require.Equal(t, s, again)
+				require.Equal(t, test.expectedShuffled, s.ShuffleExtensions)
+				orders[hexList(s.Extensions[1:len(s.Extensions)-2])] = true
+			}
+			require.Equal(t, test.expectedShuffled, len(orders) > 1)
+		})
+	}
+}
+
+func TestIsGREASE(t *testing.T) {
+	for _, v := range []uint16{0x0a0a, 0x1a1a, 0x7a7a, 0xfafa} {
+		require.True(t, IsGREASE(v), "%#04x", v)
+	}
+	for _, v := range []uint16{0x0a1a, 0x1301, 0x0000, 0xfa0a} {
+		require.False(t, IsGREASE(v), "%#04x", v)
+	}
+}
+
+func TestShuffleExtensions(t *testing.T) {
+	s, err := GenerateClientHello(3, "Chrome", "129.0")
+	require.NoError(t, err)
+	require.Equal(t, ExtensionPadding, s.Extensions[len(s.Extensions)-1])
+
+	catalog := withoutGREASE(data.Profiles[1].spec.Extensions)
+	slices.Sort(catalog)
+	shuffled := withoutGREASE(s.Extensions)
+	slices.Sort(shuffled)
+	require.Equal(t, catalog, shuffled)
+}
@@ -13,6 +13,9 @@ const (
 	SecCHUAPlatformVersionHeader
 	SecCHUAArchHeader
 	SecCHUABitnessHeader
+	SecCHUAMobileHeader
+	SecCHUAModelHeader
+	SecCHUAWoW64Header
 	UserAgentHeader
 )
 
@@ -28,6 +31,12 @@ func (h Header) String() string {
 		return "sec-ch-ua-arch"
 	case SecCHUABitnessHeader:
 		return "sec-ch-ua-bitness"
+	case SecCHUAMobileHeader:
+		return "sec-ch-ua-mobile"
+	case SecCHUAModelHeader:
+		return "sec-ch-ua-model"
+	case SecCHUAWoW64Header:
+		return "sec-ch-ua-wow64"
 	case UserAgentHeader:
 		return "user-agent"
 	default:
@@ -43,6 +52,10 @@ const (
 	PLATFORM_LINUX
 	PLATFORM_MACOS
 	PLATFORM_WINDOWS
+	PLATFORM_ANDROID
+	PLATFORM_IOS
+	PLATFORM_CHROMEOS
+	PLATFORM_IPADOS
 	END_PLATFORM
 
 	// Platform version token types
@@ -69,18 +82,72 @@ const (
 	WINDOWS_PLATFORM_VERSION_14_0_0
 	END_WINDOWS_PLATFORM_VERSION
 
+	START_ANDROID_PLATFORM_VERSION
+	ANDROID_PLATFORM_VERSION_13_0_0
+	ANDROID_PLATFORM_VERSION_14_0_0
+	ANDROID_PLATFORM_VERSION_15_0_0
+	END_ANDROID_PLATFORM_VERSION
+
+	START_IOS_PLATFORM_VERSION
+	IOS_PLATFORM_VERSION_17_4_1
+	IOS_PLATFORM_VERSION_17_5_1
+	IOS_PLATFORM_VERSION_17_6_1
+	IOS_PLATFORM_VERSION_18_0
+	END_IOS_PLATFORM_VERSION
+
+	START_CHROMEOS_PLATFORM_VERSION
+	CHROMEOS_PLATFORM_VERSION_15886_44_0
+	CHROMEOS_PLATFORM_VERSION_15917_71_0
+	CHROMEOS_PLATFORM_VERSION_15964_59_0
+	CHROMEOS_PLATFORM_VERSION_16002_44_0
+	END_CHROMEOS_PLATFORM_VERSION
+
+	START_IPADOS_PLATFORM_VERSION
+	IPADOS_PLATFORM_VERSION_17_4_1
+	IPADOS_PLATFORM_VERSION_17_5_1
+	IPADOS_PLATFORM_VERSION_17_6_1
+	IPADOS_PLATFORM_VERSION_18_0
+	END_IPADOS_PLATFORM_VERSION
+
 	// Architecture token types
 	START_ARCH
 	ARCH_X86
 	ARCH_X64
 	ARCH_ARM
+	ARCH_NONE
 	END_ARCH
 
 	// Bitness token types
 	START_BITNESS
+	BIT_32
 	BIT_64
+	BIT_NONE
 	END_BITNESS
 
+	// Mobile token types
+	START_MOBILE
+	MOBILE_FALSE
+	MOBILE_TRUE
+	END_MOBILE
+
+	// Model token types
+	MODEL_NONE
+	START_ANDROID_MODEL
+	MODEL_PIXEL_7
+	MODEL_PIXEL_8
+	MODEL_PIXEL_8_PRO
+	MODEL_PIXEL_9
+	MODEL_SM_A546B
+	MODEL_SM_S918B
+	MODEL_SM_S928B
+	END_ANDROID_MODEL
+
+	// WoW64 token types
+	START_WOW64
+	WOW64_FALSE
+	WOW64_TRUE
+	END_WOW64
+
 	// Browser identifier token types
 	MOZILLA_5_BROWSER_IDENTIFIER
 
@@ -89,9 +156,12 @@ const (
 
 	// Device type token types
 	MACINTOSH_DEVICE
+	IPHONE_DEVICE
+	ANDROID_DEVICE
 
 	// OS token types
 	LINUX
+	ANDROID_10
 
 	START_MACOS
 	MACOS_13_6_6
@@ -102,10 +172,23 @@ const (
 	MACOS_15_0
 	END_MACOS
 
+	// Safari in desktop mode reports a frozen macOS version
+	MACOS_10_15_7
+
 	START_WINDOWS
 	WINDOWS_NT_10_0
 	END_WINDOWS
 
+	START_IOS
+	IOS_17_4_1
+	IOS_17_5_1
+	IOS_17_6_1
+	IOS_18_0
+	END_IOS
+
+	// Chrome on ChromeOS reports a frozen platform version
+	CHROMEOS_14541_0_0
+
 	// OS bitness token types
 	WIN64_ARCH
 
@@ -115,15 +198,25 @@ const (
 	X86_64_PROC_ARCH
 	END_PROC_ARCH
 
+	// Device model token types
+	REDUCED_DEVICE_MODEL
+
 	// Rendering engine token types
 	START_APPLE_WEBKIT
 	APPLE_WEBKIT_537_36
+	APPLE_WEBKIT_605_1_15
 	END_APPLE_WEBKIT
 
 	START_SAFARI_WEBKIT
 	SAFARI_WEBKIT_537_36
+	MOBILE_SAFARI_WEBKIT_537_36
+	SAFARI_WEBKIT_604_1
+	SAFARI_WEBKIT_605_1_15
 	END_SAFARI_WEBKIT
 
+	// Mobile build token types
+	MOBILE_BUILD_15E148
+
 	// Additional info token types
 	KHTML_ADDITIONAL_INFO
 
@@ -140,6 +233,16 @@ const (
 	CHROME_128_0
 	CHROME_129_0
 	END_CHROME
+
+	START_SAFARI
+	SAFARI_17_4
+	SAFARI_17_5
+	SAFARI_17_6
+	SAFARI_18_0
+	END_SAFARI
+
+	// END_TOKEN must remain the last token type
+	END_TOKEN
 )
 
 type TokenType int
@@ -152,6 +255,14 @@ func (t TokenType) String() string {
 		return "macOS"
 	case PLATFORM_WINDOWS:
 		return "Windows"
+	case PLATFORM_ANDROID:
+		return "Android"
+	case PLATFORM_IOS:
+		return "iOS"
+	case PLATFORM_CHROMEOS:
+		return "Chrome OS"
+	case PLATFORM_IPADOS:
+		return "iPadOS"
 	case LINUX_PLATFORM_VERSION_5_18_11:
 		return "5.18.11"
 	case LINUX_PLATFORM_VERSION_5_19_15:
@@ -180,22 +291,82 @@ func (t TokenType) String() string {
 		return "10.0.0"
 	case WINDOWS_PLATFORM_VERSION_14_0_0:
 		return "14.0.0"
+	case ANDROID_PLATFORM_VERSION_13_0_0:
+		return "13.0.0"
+	case ANDROID_PLATFORM_VERSION_14_0_0:
+		return "14.0.0"
+	case ANDROID_PLATFORM_VERSION_15_0_0:
+		return "15.0.0"
+	case IOS_PLATFORM_VERSION_17_4_1:
+		return "17.4.1"
+	case IOS_PLATFORM_VERSION_17_5_1:
+		return "17.5.1"
+	case IOS_PLATFORM_VERSION_17_6_1:
+		return "17.6.1"
+	case IOS_PLATFORM_VERSION_18_0:
+		return "18.0"
+	case CHROMEOS_PLATFORM_VERSION_15886_44_0:
+		return "15886.44.0"
+	case CHROMEOS_PLATFORM_VERSION_15917_71_0:
+		return "15917.71.0"
+	case CHROMEOS_PLATFORM_VERSION_15964_59_0:
+		return "15964.59.0"
+	case CHROMEOS_PLATFORM_VERSION_16002_44_0:
+		return "16002.44.0"
+	case IPADOS_PLATFORM_VERSION_17_4_1:
+		return "17.4.1"
+	case IPADOS_PLATFORM_VERSION_17_5_1:
+		return "17.5.1"
+	case IPADOS_PLATFORM_VERSION_17_6_1:
+		return "17.6.1"
+	case IPADOS_PLATFORM_VERSION_18_0:
+		return "18.0"
 	case ARCH_X86:
 		return "x86"
 	case ARCH_X64:
 		return "x64"
 	case ARCH_ARM:
 		return "arm"
+	case BIT_32:
+		return "32"
 	case BIT_64:
 		return "64"
+	case MOBILE_FALSE:
+		return "?0"
+	case MOBILE_TRUE:
+		return "?1"
+	case MODEL_PIXEL_7:
+		return "Pixel 7"
+	case MODEL_PIXEL_8:
+		return "Pixel 8"
+	case MODEL_PIXEL_8_PRO:
+		return "Pixel 8 Pro"
+	case MODEL_PIXEL_9:
+		return "Pixel 9"
+	case MODEL_SM_A546B:
+		return "SM-A546B"
+	case MODEL_SM_S918B:
+		return "SM-S918B"
+	case MODEL_SM_S928B:
+		return "SM-S928B"
+	case WOW64_FALSE:
+		return "?0"
+	case WOW64_TRUE:
+		return "?1"
 	case MOZILLA_5_BROWSER_IDENTIFIER:
 		return "Mozilla/5.0"
 	case X11_WINDOW_SYSTEM:
 		return "(X11;"
 	case MACINTOSH_DEVICE:
 		return "(Macintosh;"
+	case IPHONE_DEVICE:
+		return "(iPhone;"
+	case ANDROID_DEVICE:
+		return "(Linux;"
 	case LINUX:
 		return "Linux"
+	case ANDROID_10:
+		return "Android 10;"
 	case MACOS_13_6_6:
 		return "Intel Mac OS X 13_6_6)"
 	case MACOS_13_7:
@@ -208,20 +379,44 @@ func (t TokenType) String() string {
 		return "Intel Mac OS X 14_7)"
 	case MACOS_15_0:
 		return "Intel Mac OS X 15_0)"
+	case MACOS_10_15_7:
+		return "Intel Mac OS X 10_15_7)"
 	case WINDOWS_NT_10_0:
 		return "(Windows NT 10.0;"
+	case IOS_17_4_1:
+		return "CPU iPhone OS 17_4_1 like Mac OS X)"
+	case IOS_17_5_1:
+		return "CPU iPhone OS 17_5_1 like Mac OS X)"
+	case IOS_17_6_1:
+		return "CPU iPhone OS 17_6_1 like Mac OS X)"
+	case IOS_18_0:
+		return "CPU iPhone OS 18_0 like Mac OS X)"
+	case CHROMEOS_14541_0_0:
+		return "CrOS x86_64 14541.0.0)"
 	case WIN64_ARCH:
 		return "Win64;"
 	case X64_PROC_ARCH:
 		return "x64)"
 	case X86_64_PROC_ARCH:
 		return "x86_64)"
+	case REDUCED_DEVICE_MODEL:
+		return "K)"
 	case APPLE_WEBKIT_537_36:
 		return "AppleWebKit/537.36"
+	case APPLE_WEBKIT_605_1_15:
+		return "AppleWebKit/605.1.15"
 	case KHTML_ADDITIONAL_INFO:
 		return "(KHTML, like Gecko)"
 	case SAFARI_WEBKIT_537_36:
 		return "Safari/537.36"
+	case MOBILE_SAFARI_WEBKIT_537_36:
+		return "Mobile Safari/537.36"
+	case SAFARI_WEBKIT_604_1:
+		return "Safari/604.1"
+	case SAFARI_WEBKIT_605_1_15:
+		return "Safari/605.1.15"
+	case MOBILE_BUILD_15E148:
+		return "Mobile/15E148"
 	case CHROME_120_0:
 		return "Chrome/120.0.0.0"
 	case CHROME_121_0:
@@ -242,6 +437,14 @@ func (t TokenType) String() string {
 		return "Chrome/128.0.0.0"
 	case CHROME_129_0:
 		return "Chrome/129.0.0.0"
+	case SAFARI_17_4:
+		return "Version/17.4"
+	case SAFARI_17_5:
+		return "Version/17.5"
+	case SAFARI_17_6:
+		return "Version/17.6"
+	case SAFARI_18_0:
+		return "Version/18.0"
 	default:
 		return ""
 	}
@@ -279,12 +482,12 @@ func NewToken(seed int64, opts ...Option) *Token {
 		opt(&o)
 	}
 
-	possibilities := make([]TokenType, 0, END_CHROME)
+	possibilities := make([]TokenType, 0, END_TOKEN)
 	if len(o.AllowedTokens) > 0 {
 		possibilities = make([]TokenType, len(o.AllowedTokens))
 		copy(possibilities, o.AllowedTokens)
 	} else {
-		for i := TokenType(0); i < END_CHROME; i++ {
+		for i := TokenType(0); i < END_TOKEN; i++ {
 			possibilities = append(possibilities, TokenType(i))
 		}
 	}
@@ -305,6 +508,8 @@ func NewToken(seed int64, opts ...Option) *Token {
 }
 
 type UserAgent struct {
+	// Headers holds the generated header values keyed by header name.
+	// Client hint headers are omitted for browsers that do not send them.
 	Headers map[string]string
 	tokens  []*Token
 }
@@ -324,6 +529,9 @@ func NewUserAgent(length int, seed int64, opts ...Option) *UserAgent {
 			SecCHUAPlatformVersionHeader.String(): "",
 			SecCHUAArchHeader.String():            "",
 			SecCHUABitnessHeader.String():         "",
+			SecCHUAMobileHeader.String():          "",
+			SecCHUAModelHeader.String():           "",
+			SecCHUAWoW64Header.String():           "",
 			UserAgentHeader.String():              "",
 		},
 		tokens: tokens,
@@ -350,13 +558,39 @@ func (ua *UserAgent) generate() {
 
 func (ua *UserAgent) updateHeaders() {
 	for i, token := range ua.tokens {
-		if i < 4 {
+		if len(token.Possibilities) == 0 {
+			break
+		}
+		if i < int(UserAgentHeader)-1 {
 			ua.Headers[Header(i+1).String()] = token.Possibilities[0].String()
-		} else if i < len(ua.tokens) && len(token.Possibilities) > 0 {
+		} else {
 			ua.Headers[UserAgentHeader.String()] += fmt.Sprintf("%s ", token.Possibilities[0].String())
 		}
 	}
 	ua.Headers[UserAgentHeader.String()] = strings.TrimSpace(ua.Headers[UserAgentHeader.String()])
+
+	// Safari does not implement User-Agent Client Hints
+	if ua.hasToken(START_SAFARI, END_SAFARI) {
+		for h := SecCHUAPlatformHeader; h < UserAgentHeader; h++ {
+			delete(ua.Headers, h.String())
+		}
+	}
+}
+
+// Token returns the collapsed token lying within the given range, e.g. START_PLATFORM and END_PLATFORM.
+func (ua *UserAgent) Token(start, end TokenType) (TokenType, bool) {
+	for _, token := range ua.tokens {
+		if len(token.Possibilities) == 1 && In(token.Possibilities[0], start, end) {
+			return token.Possibilities[0], true
+		}
+	}
+	return 0, false
+}
+
+// hasToken reports whether any collapsed token lies within the given range.
+func (ua *UserAgent) hasToken(start, end TokenType) bool {
+	_, ok := ua.Token(start, end)
+	return ok
 }
 
 func (t *Token) Collapse() TokenType {
@@ -393,7 +627,11 @@ func isCompatible(collapsed, prev, current TokenType) bool {
 	isPlatformVersion := func(token TokenType) bool {
 		return In(token, START_LINUX_PLATFORM_VERSION, END_LINUX_PLATFORM_VERSION) ||
 			In(token, START_MACOS_PLATFORM_VERSION, END_MACOS_PLATFORM_VERSION) ||
-			In(token, START_WINDOWS_PLATFORM_VERSION, END_WINDOWS_PLATFORM_VERSION)
+			In(token, START_WINDOWS_PLATFORM_VERSION, END_WINDOWS_PLATFORM_VERSION) ||
+			In(token, START_ANDROID_PLATFORM_VERSION, END_ANDROID_PLATFORM_VERSION) ||
+			In(token, START_IOS_PLATFORM_VERSION, END_IOS_PLATFORM_VERSION) ||
+			In(token, START_CHROMEOS_PLATFORM_VERSION, END_CHROMEOS_PLATFORM_VERSION) ||
+			In(token, START_IPADOS_PLATFORM_VERSION, END_IPADOS_PLATFORM_VERSION)
 	}
 
 	isArch := func(token TokenType) bool {
@@ -404,10 +642,26 @@ func isCompatible(collapsed, prev, current TokenType) bool {
 		return In(token, START_BITNESS, END_BITNESS)
 	}
 
+	isMobile := func(token TokenType) bool {
+		return In(token, START_MOBILE, END_MOBILE)
+	}
+
+	isModel := func(token TokenType) bool {
+		return token == MODEL_NONE || In(token, START_ANDROID_MODEL, END_ANDROID_MODEL)
+	}
+
+	isWoW64 := func(token TokenType) bool {
+		return In(token, START_WOW64, END_WOW64)
+	}
+
 	isChromeVersion := func(token TokenType) bool {
 		return In(token, START_CHROME, END_CHROME)
 	}
 
+	isSafariVersion := func(token TokenType) bool {
+		return In(token, START_SAFARI, END_SAFARI)
+	}
+
 	// First token must be a platform
 	if prev == 0 {
 		return isPlatform(current)
@@ -422,6 +676,14 @@ func isCompatible(collapsed, prev, current TokenType) bool {
 			return In(current, START_MACOS_PLATFORM_VERSION, END_MACOS_PLATFORM_VERSION)
 		case PLATFORM_WINDOWS:
 			return In(current, START_WINDOWS_PLATFORM_VERSION, END_WINDOWS_PLATFORM_VERSION)
+		case PLATFORM_ANDROID:
+			return In(current, START_ANDROID_PLATFORM_VERSION, END_ANDROID_PLATFORM_VERSION)
+		case PLATFORM_IOS:
+			return In(current, START_IOS_PLATFORM_VERSION, END_IOS_PLATFORM_VERSION)
+		case PLATFORM_CHROMEOS:
+			return In(current, START_CHROMEOS_PLATFORM_VERSION, END_CHROMEOS_PLATFORM_VERSION)
+		case PLATFORM_IPADOS:
+			return In(current, START_IPADOS_PLATFORM_VERSION, END_IPADOS_PLATFORM_VERSION)
 		}
 		return false
 	}
@@ -430,56 +692,141 @@ func isCompatible(collapsed, prev, current TokenType) bool {
 	if isPlatformVersion(prev) {
 		switch collapsed {
 		case PLATFORM_LINUX:
+			return current == ARCH_X86 || current == ARCH_ARM
+		case PLATFORM_CHROMEOS:
 			return current == ARCH_X86
 		case PLATFORM_WINDOWS:
-			return current == ARCH_X64
-		case PLATFORM_MACOS:
+			return current == ARCH_X64 || current == ARCH_X86 || current == ARCH_ARM
+		case PLATFORM_MACOS, PLATFORM_IOS, PLATFORM_IPADOS:
 			return current == ARCH_ARM
+		case PLATFORM_ANDROID:
+			// Chrome on Android leaves the architecture hint empty
+			return current == ARCH_NONE
+		case WINDOWS_PLATFORM_VERSION_10_0_0:
+			// ARM64 Windows devices ship with Windows 11
+			return current != ARCH_ARM
 		}
 		return true
 	}
 
 	// Fourth token must be a bitness
 	if isArch(prev) {
-		return isBitness(current)
+		switch {
+		case prev == ARCH_NONE:
+			return current == BIT_NONE
+		case prev != ARCH_X86:
+			return current == BIT_64
+		case collapsed == PLATFORM_WINDOWS:
+			// 32-bit Chrome running under WoW64 reports x86 as well
+			return current == BIT_32 || current == BIT_64
+		case isPlatform(collapsed):
+			return current == BIT_64
+		}
+		return current == BIT_32 || current == BIT_64
 	}
 
-	// Fifth token must be MOZILLA_5_BROWSER_IDENTIFIER
+	// Fifth token must be the mobile flag
 	if isBitness(prev) {
+		switch collapsed {
+		case PLATFORM_ANDROID, PLATFORM_IOS:
+			return current == MOBILE_TRUE
+		case PLATFORM_LINUX, PLATFORM_MACOS, PLATFORM_WINDOWS, PLATFORM_CHROMEOS, PLATFORM_IPADOS:
+			return current == MOBILE_FALSE
+		}
+		return true
+	}
+
+	// Sixth token must be a device model, which only Android reports
+	if isMobile(prev) {
+		switch {
+		case collapsed == PLATFORM_ANDROID:
+			return In(current, START_ANDROID_MODEL, END_ANDROID_MODEL)
+		case isPlatform(collapsed):
+			return current == MODEL_NONE
+		case In(collapsed, START_ANDROID_PLATFORM_VERSION, END_ANDROID_PLATFORM_VERSION):
+			return collapsed >= minAndroidPlatformVersion(current)
+		}
+		return true
+	}
+
+	// Seventh token must be the WoW64 flag, which is only set for 32-bit Chrome on Windows
+	if isModel(prev) {
+		switch {
+		case collapsed == PLATFORM_WINDOWS:
+			return isWoW64(current)
+		case isPlatform(collapsed):
+			return current == WOW64_FALSE
+		case collapsed == BIT_32:
+			return current == WOW64_TRUE
+		case isBitness(collapsed):
+			return current == WOW64_FALSE
+		}
+		return true
+	}
+
+	// Eighth token must be MOZILLA_5_BROWSER_IDENTIFIER
+	if isWoW64(prev) {
 		return current == MOZILLA_5_BROWSER_IDENTIFIER
 	}
 
 	// After MOZILLA_5_BROWSER_IDENTIFIER, we expect the window system or device type or OS
 	if prev == MOZILLA_5_BROWSER_IDENTIFIER {
 		switch collapsed {
-		case PLATFORM_LINUX:
+		case PLATFORM_LINUX, PLATFORM_CHROMEOS:
 			return current == X11_WINDOW_SYSTEM
-		case PLATFORM_MACOS:
+		case PLATFORM_MACOS, PLATFORM_IPADOS:
 			return current == MACINTOSH_DEVICE
 		case PLATFORM_WINDOWS:
 			return current == WINDOWS_NT_10_0
+		case PLATFORM_ANDROID:
+			return current == ANDROID_DEVICE
+		case PLATFORM_IOS:
+			return current == IPHONE_DEVICE
 		}
 		return true
 	}
 
 	// After window system or device type, we expect the OS
 	if prev == X11_WINDOW_SYSTEM {
-		return current == LINUX
+		switch collapsed {
+		case PLATFORM_LINUX:
+			return current == LINUX
+		case PLATFORM_CHROMEOS:
+			return current == CHROMEOS_14541_0_0
+		}
+		return true
+	}
+
+	if prev == ANDROID_DEVICE {
+		return current == ANDROID_10
 	}
 
 	if prev == MACINTOSH_DEVICE {
-		if In(collapsed, START_MACOS_PLATFORM_VERSION, END_MACOS_PLATFORM_VERSION) {
+		switch {
+		case collapsed == PLATFORM_MACOS:
+			return In(current, START_MACOS, END_MACOS)
+		case collapsed == PLATFORM_IPADOS:
+			return current == MACOS_10_15_7
+		case In(collapsed, START_MACOS_PLATFORM_VERSION, END_MACOS_PLATFORM_VERSION):
 			platformVersionOffset := int(collapsed) - int(START_MACOS_PLATFORM_VERSION)
 			return int(current) == int(START_MACOS)+platformVersionOffset
 		}
 		return true
 	}
 
+	if prev == IPHONE_DEVICE {
+		if In(collapsed, START_IOS_PLATFORM_VERSION, END_IOS_PLATFORM_VERSION) {
+			platformVersionOffset := int(collapsed) - int(START_IOS_PLATFORM_VERSION)
+			return int(current) == int(START_IOS)+platformVersionOffset
+		}
+		return In(current, START_IOS, END_IOS)
+	}
+
 	if prev == WINDOWS_NT_10_0 {
 		return current == WIN64_ARCH
 	}
 
-	// After OS, we expect the processor architecture
+	// After OS, we expect the processor architecture or the reduced device model
 	if prev == LINUX {
 		return current == X86_64_PROC_ARCH
 	}
@@ -488,33 +835,100 @@ func isCompatible(collapsed, prev, current TokenType) bool {
 		return current == X64_PROC_ARCH
 	}
 
-	// After processor architecture, we expect AppleWebKit
-	if In(prev, START_PROC_ARCH, END_PROC_ARCH) || In(prev, START_MACOS, END_MACOS) {
+	if prev == ANDROID_10 {
+		return current == REDUCED_DEVICE_MODEL
+	}
+
+	// After processor architecture or device model, we expect AppleWebKit
+	if In(prev, START_PROC_ARCH, END_PROC_ARCH) || In(prev, START_MACOS, END_MACOS) ||
+		prev == REDUCED_DEVICE_MODEL || prev == CHROMEOS_14541_0_0 {
 		return current == APPLE_WEBKIT_537_36
 	}
 
+	if In(prev, START_IOS, END_IOS) || prev == MACOS_10_15_7 {
+		return current == APPLE_WEBKIT_605_1_15
+	}
+
 	// After AppleWebKit, we expect KHTML additional info
-	if prev == APPLE_WEBKIT_537_36 {
+	if In(prev, START_APPLE_WEBKIT, END_APPLE_WEBKIT) {
 		return current == KHTML_ADDITIONAL_INFO
 	}
 
-	// After KHTML, we expect Chrome version
+	// After KHTML, we expect the browser version
 	if prev == KHTML_ADDITIONAL_INFO {
-		return isChromeVersion(current)
+		switch {
+		case collapsed == PLATFORM_IOS || collapsed == PLATFORM_IPADOS:
+			return isSafariVersion(current)
+		case isPlatform(collapsed):
+			return isChromeVersion(current)
+		case In(collapsed, START_IOS_PLATFORM_VERSION, END_IOS_PLATFORM_VERSION):
+			platformVersionOffset := int(collapsed) - int(START_IOS_PLATFORM_VERSION)
+			return int(current) == int(START_SAFARI)+platformVersionOffset
+		case In(collapsed, START_IPADOS_PLATFORM_VERSION, END_IPADOS_PLATFORM_VERSION):
+			platformVersionOffset := int(collapsed) - int(START_IPADOS_PLATFORM_VERSION)
+			return int(current) == int(START_SAFARI)+platformVersionOffset
+		case In(collapsed, START_CHROMEOS_PLATFORM_VERSION, END_CHROMEOS_PLATFORM_VERSION):
+			return current == chromeOSChromeVersion(collapsed)
+		}
+		return true
 	}
 
 	// After Chrome version, we expect SafariWebKit
 	if isChromeVersion(prev) {
-		if collapsed == APPLE_WEBKIT_537_36 {
+		switch collapsed {
+		case PLATFORM_ANDROID:
+			return current == MOBILE_SAFARI_WEBKIT_537_36
+		case PLATFORM_LINUX, PLATFORM_MACOS, PLATFORM_WINDOWS, PLATFORM_CHROMEOS:
 			return current == SAFARI_WEBKIT_537_36
 		}
 		return true
 	}
 
+	// After Safari version, we expect the mobile build and then SafariWebKit
+	if isSafariVersion(prev) {
+		switch collapsed {
+		case PLATFORM_IOS:
+			return current == MOBILE_BUILD_15E148
+		case PLATFORM_IPADOS:
+			return current == SAFARI_WEBKIT_605_1_15
+		}
+		return true
+	}
+
+	if prev == MOBILE_BUILD_15E148 {
+		return current == SAFARI_WEBKIT_604_1
+	}
+
 	// No more tokens expected after SafariWebKit
 	return false
 }
 
+// chromeOSChromeVersion returns the Chrome version bundled with the ChromeOS release.
+func chromeOSChromeVersion(platformVersion TokenType) TokenType {
+	switch platformVersion {
+	case CHROMEOS_PLATFORM_VERSION_15886_44_0:
+		return CHROME_126_0
+	case CHROMEOS_PLATFORM_VERSION_15917_71_0:
+		return CHROME_127_0
+	case CHROMEOS_PLATFORM_VERSION_15964_59_0:
+		return CHROME_128_0
+	case CHROMEOS_PLATFORM_VERSION_16002_44_0:
+		return CHROME_129_0
+	default:
+		return 0
+	}
+}
+
+// minAndroidPlatformVersion returns the Android version the device model shipped with.
+func minAndroidPlatformVersion(model TokenType) TokenType {
+	switch model {
+	case MODEL_PIXEL_8, MODEL_PIXEL_8_PRO, MODEL_PIXEL_9, MODEL_SM_S928B:
+		return ANDROID_PLATFORM_VERSION_14_0_0
+	default:
+		return ANDROID_PLATFORM_VERSION_13_0_0
+	}
+}
+
 func In(token, start, end TokenType) bool {
 	return token > start && token < end
 }
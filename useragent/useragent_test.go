@@ -22,8 +22,7 @@ func TestTokenObserve(t *testing.T) {
 
 	current.Observe(collapsed, prev)
 
-	require.Equal(t, 1, len(current.Possibilities))
-	require.Equal(t, ARCH_X86, current.Possibilities[0])
+	require.ElementsMatch(t, []TokenType{ARCH_X86, ARCH_ARM}, current.Possibilities)
 }
 
 func TestNewUserAgent(t *testing.T) {
@@ -36,19 +35,40 @@ func TestNewUserAgent(t *testing.T) {
 	require.NotEmpty(t, ua.Headers[UserAgentHeader.String()])
 
 	// Additional checks for header format
-	require.Regexp(t, `^(Linux|macOS|Windows)$`, ua.Headers[SecCHUAPlatformHeader.String()])
+	require.Regexp(t, `^(Linux|macOS|Windows|Chrome OS)$`, ua.Headers[SecCHUAPlatformHeader.String()])
 	require.Regexp(t, `^\d+\.\d+(\.\d+)?$`, ua.Headers[SecCHUAPlatformVersionHeader.String()])
 	require.Regexp(t, `^(x86|x64|arm)$`, ua.Headers[SecCHUAArchHeader.String()])
 	require.Equal(t, "64", ua.Headers[SecCHUABitnessHeader.String()])
+	require.Regexp(t, `^\?[01]$`, ua.Headers[SecCHUAWoW64Header.String()])
 	require.Regexp(t, `^Mozilla/5\.0 .+ AppleWebKit/537\.36 .+ Chrome/\d+\.\d+\.\d+\.\d+ Safari/537\.36$`, ua.Headers[UserAgentHeader.String()])
 }
 
+func TestUserAgentToken(t *testing.T) {
+	ua := NewUserAgent(20, 42, WithCondition(func(tt TokenType) bool {
+		return !In(tt, START_PLATFORM, END_PLATFORM) || tt == PLATFORM_MACOS
+	}))
+
+	platform, ok := ua.Token(START_PLATFORM, END_PLATFORM)
+	require.True(t, ok)
+	require.Equal(t, PLATFORM_MACOS, platform)
+
+	v, ok := ua.Token(START_MACOS_PLATFORM_VERSION, END_MACOS_PLATFORM_VERSION)
+	require.True(t, ok)
+	require.Equal(t, ua.Headers[SecCHUAPlatformVersionHeader.String()], v.String())
+
+	_, ok = ua.Token(START_WINDOWS_PLATFORM_VERSION, END_WINDOWS_PLATFORM_VERSION)
+	require.False(t, ok)
+}
+
 func TestNewUserAgentWithAllowedTokens(t *testing.T) {
 	allowedTokens := []TokenType{
 		PLATFORM_LINUX,
 		LINUX_PLATFORM_VERSION_5_18_11,
 		ARCH_X86,
 		BIT_64,
+		MOBILE_FALSE,
+		MODEL_NONE,
+		WOW64_FALSE,
 		MOZILLA_5_BROWSER_IDENTIFIER,
 		X11_WINDOW_SYSTEM,
 		LINUX,
@@ -66,6 +86,9 @@ func TestNewUserAgentWithAllowedTokens(t *testing.T) {
 		SecCHUAPlatformVersionHeader.String(): "5.18.11",
 		SecCHUAArchHeader.String():            "x86",
 		SecCHUABitnessHeader.String():         "64",
+		SecCHUAMobileHeader.String():          "?0",
+		SecCHUAModelHeader.String():           "",
+		SecCHUAWoW64Header.String():           "?0",
 		UserAgentHeader.String():              "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
 	}
 
@@ -74,6 +97,88 @@ func TestNewUserAgentWithAllowedTokens(t *testing.T) {
 	}
 }
 
+func TestNewUserAgentAndroid(t *testing.T) {
+	ua := NewUserAgent(20, 42, WithCondition(func(tt TokenType) bool {
+		return !In(tt, START_PLATFORM, END_PLATFORM) || tt == PLATFORM_ANDROID
+	}))
+
+	require.Equal(t, "Android", ua.Headers[SecCHUAPlatformHeader.String()])
+	require.Regexp(t, `^1[345]\.0\.0$`, ua.Headers[SecCHUAPlatformVersionHeader.String()])
+	require.Empty(t, ua.Headers[SecCHUAArchHeader.String()])
+	require.Empty(t, ua.Headers[SecCHUABitnessHeader.String()])
+	require.Equal(t, "?1", ua.Headers[SecCHUAMobileHeader.String()])
+	require.NotEmpty(t, ua.Headers[SecCHUAModelHeader.String()])
+	require.Regexp(t, `^Mozilla/5\.0 \(Linux; Android 10; K\) AppleWebKit/537\.36 \(KHTML, like Gecko\) Chrome/\d+\.0\.0\.0 Mobile Safari/537\.36$`, ua.Headers[UserAgentHeader.String()])
+}
+
+func TestNewUserAgentIOS(t *testing.T) {
+	ua := NewUserAgent(20, 42, WithCondition(func(tt TokenType) bool {
+		return !In(tt, START_PLATFORM, END_PLATFORM) || tt == PLATFORM_IOS
+	}))
+
+	for h := SecCHUAPlatformHeader; h < UserAgentHeader; h++ {
+		require.NotContains(t, ua.Headers, h.String())
+	}
+	require.Regexp(t, `^Mozilla/5\.0 \(iPhone; CPU iPhone OS (\d+)_(\d+)(_\d+)? like Mac OS X\) AppleWebKit/605\.1\.15 \(KHTML, like Gecko\) Version/\d+\.\d+ Mobile/15E148 Safari/604\.1$`, ua.Headers[UserAgentHeader.String()])
+}
+
+func TestNewUserAgentChromeOS(t *testing.T) {
+	ua := NewUserAgent(20, 42, WithCondition(func(tt TokenType) bool {
+		return !In(tt, START_PLATFORM, END_PLATFORM) || tt == PLATFORM_CHROMEOS
+	}))
+
+	require.Equal(t, "Chrome OS", ua.Headers[SecCHUAPlatformHeader.String()])
+	require.Regexp(t, `^\d{5}\.\d+\.0$`, ua.Headers[SecCHUAPlatformVersionHeader.String()])
+	require.Equal(t, "?0", ua.Headers[SecCHUAMobileHeader.String()])
+	require.Regexp(t, `^Mozilla/5\.0 \(X11; CrOS x86_64 14541\.0\.0\) AppleWebKit/537\.36 \(KHTML, like Gecko\) Chrome/\d+\.0\.0\.0 Safari/537\.36$`, ua.Headers[UserAgentHeader.String()])
+}
+
+func TestNewUserAgentIPadOS(t *testing.T) {
+	ua := NewUserAgent(20, 42, WithCondition(func(tt TokenType) bool {
+		return !In(tt, START_PLATFORM, END_PLATFORM) || tt == PLATFORM_IPADOS
+	}))
+
+	for h := SecCHUAPlatformHeader; h < UserAgentHeader; h++ {
+		require.NotContains(t, ua.Headers, h.String())
+	}
+	require.Regexp(t, `^Mozilla/5\.0 \(Macintosh; Intel Mac OS X 10_15_7\) AppleWebKit/605\.1\.15 \(KHTML, like Gecko\) Version/\d+\.\d+ Safari/605\.1\.15$`, ua.Headers[UserAgentHeader.String()])
+}
+
+func TestNewUserAgentWindowsArchitectures(t *testing.T) {
+	testCases := []struct {
+		name    string
+		arch    TokenType
+		bitness TokenType
+		wow64   string
+	}{
+		{"x64", ARCH_X64, BIT_64, "?0"},
+		{"WoW64", ARCH_X86, BIT_32, "?1"},
+		{"ARM64", ARCH_ARM, BIT_64, "?0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ua := NewUserAgent(20, 42, WithCondition(func(tt TokenType) bool {
+				switch {
+				case In(tt, START_PLATFORM, END_PLATFORM):
+					return tt == PLATFORM_WINDOWS
+				case In(tt, START_ARCH, END_ARCH):
+					return tt == tc.arch
+				case In(tt, START_BITNESS, END_BITNESS):
+					return tt == tc.bitness
+				}
+				return true
+			}))
+
+			require.Equal(t, "Windows", ua.Headers[SecCHUAPlatformHeader.String()])
+			require.Equal(t, tc.arch.String(), ua.Headers[SecCHUAArchHeader.String()])
+			require.Equal(t, tc.bitness.String(), ua.Headers[SecCHUABitnessHeader.String()])
+			require.Equal(t, tc.wow64, ua.Headers[SecCHUAWoW64Header.String()])
+			require.Contains(t, ua.Headers[UserAgentHeader.String()], "(Windows NT 10.0; Win64; x64)")
+		})
+	}
+}
+
 func TestIsCompatible(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -97,9 +202,37 @@ func TestIsCompatible(t *testing.T) {
 		{"KHTML Info", APPLE_WEBKIT_537_36, APPLE_WEBKIT_537_36, KHTML_ADDITIONAL_INFO, true},
 		{"Chrome Version", KHTML_ADDITIONAL_INFO, KHTML_ADDITIONAL_INFO, CHROME_120_0, true},
 		{"Safari WebKit", CHROME_120_0, CHROME_120_0, SAFARI_WEBKIT_537_36, true},
+		{"Android Architecture", PLATFORM_ANDROID, ANDROID_PLATFORM_VERSION_14_0_0, ARCH_NONE, true},
+		{"Android Bitness", ARCH_NONE, ARCH_NONE, BIT_NONE, true},
+		{"Android Mobile", PLATFORM_ANDROID, BIT_NONE, MOBILE_TRUE, true},
+		{"Android Model", PLATFORM_ANDROID, MOBILE_TRUE, MODEL_PIXEL_8, true},
+		{"Android Model Version", ANDROID_PLATFORM_VERSION_14_0_0, MOBILE_TRUE, MODEL_PIXEL_8, true},
+		{"Mobile Safari WebKit", PLATFORM_ANDROID, CHROME_129_0, MOBILE_SAFARI_WEBKIT_537_36, true},
+		{"iOS Device", PLATFORM_IOS, MOZILLA_5_BROWSER_IDENTIFIER, IPHONE_DEVICE, true},
+		{"iOS OS", IOS_PLATFORM_VERSION_17_6_1, IPHONE_DEVICE, IOS_17_6_1, true},
+		{"Safari Version", IOS_PLATFORM_VERSION_17_6_1, KHTML_ADDITIONAL_INFO, SAFARI_17_6, true},
+		{"ChromeOS Window System", PLATFORM_CHROMEOS, MOZILLA_5_BROWSER_IDENTIFIER, X11_WINDOW_SYSTEM, true},
+		{"ChromeOS OS", PLATFORM_CHROMEOS, X11_WINDOW_SYSTEM, CHROMEOS_14541_0_0, true},
+		{"ChromeOS Chrome Version", CHROMEOS_PLATFORM_VERSION_16002_44_0, KHTML_ADDITIONAL_INFO, CHROME_129_0, true},
+		{"iPadOS Device", PLATFORM_IPADOS, MACINTOSH_DEVICE, MACOS_10_15_7, true},
+		{"iPadOS Safari WebKit", PLATFORM_IPADOS, SAFARI_18_0, SAFARI_WEBKIT_605_1_15, true},
+		{"Windows ARM64 Architecture", PLATFORM_WINDOWS, WINDOWS_PLATFORM_VERSION_14_0_0, ARCH_ARM, true},
+		{"Windows WoW64 Bitness", PLATFORM_WINDOWS, ARCH_X86, BIT_32, true},
+		{"Windows WoW64", BIT_32, MODEL_NONE, WOW64_TRUE, true},
+		{"Linux ARM64 Architecture", PLATFORM_LINUX, LINUX_PLATFORM_VERSION_6_10_11, ARCH_ARM, true},
 		{"Incompatible Linux Version", PLATFORM_LINUX, PLATFORM_LINUX, MACOS_PLATFORM_VERSION_13_6_6, false},
 		{"Incompatible MacOS Architecture", PLATFORM_MACOS, MACOS_PLATFORM_VERSION_13_6_6, ARCH_X86, false},
-		{"Incompatible Windows Architecture", PLATFORM_WINDOWS, WINDOWS_PLATFORM_VERSION_10_0_0, ARCH_ARM, false},
+		{"Incompatible Windows Architecture", WINDOWS_PLATFORM_VERSION_10_0_0, WINDOWS_PLATFORM_VERSION_10_0_0, ARCH_ARM, false},
+		{"Incompatible ARM Bitness", ARCH_ARM, ARCH_ARM, BIT_32, false},
+		{"Incompatible Linux Bitness", PLATFORM_LINUX, ARCH_X86, BIT_32, false},
+		{"Incompatible WoW64", BIT_64, MODEL_NONE, WOW64_TRUE, false},
+		{"Incompatible Desktop Mobile", PLATFORM_LINUX, BIT_64, MOBILE_TRUE, false},
+		{"Incompatible Desktop Model", PLATFORM_WINDOWS, MOBILE_FALSE, MODEL_PIXEL_8, false},
+		{"Incompatible Android Model Version", ANDROID_PLATFORM_VERSION_13_0_0, MOBILE_TRUE, MODEL_PIXEL_8, false},
+		{"Incompatible Desktop Safari WebKit", PLATFORM_LINUX, CHROME_129_0, MOBILE_SAFARI_WEBKIT_537_36, false},
+		{"Incompatible ChromeOS Chrome Version", CHROMEOS_PLATFORM_VERSION_16002_44_0, KHTML_ADDITIONAL_INFO, CHROME_120_0, false},
+		{"Incompatible iPadOS Device", PLATFORM_IPADOS, MACINTOSH_DEVICE, MACOS_15_0, false},
+		{"Incompatible iOS Safari Version", IOS_PLATFORM_VERSION_17_6_1, KHTML_ADDITIONAL_INFO, SAFARI_18_0, false},
 	}
 
 	for _, tc := range testCases {
@@ -139,6 +272,9 @@ func TestFilterTokens(t *testing.T) {
 		LINUX_PLATFORM_VERSION_5_18_11,
 		ARCH_X86,
 		BIT_64,
+		MOBILE_FALSE,
+		MODEL_NONE,
+		WOW64_FALSE,
 		MOZILLA_5_BROWSER_IDENTIFIER,
 		X11_WINDOW_SYSTEM,
 		LINUX,
@@ -205,6 +341,9 @@ func BenchmarkNewUserAgent(b *testing.B) {
 				LINUX_PLATFORM_VERSION_5_18_11,
 				ARCH_X86,
 				BIT_64,
+				MOBILE_FALSE,
+				MODEL_NONE,
+				WOW64_FALSE,
 				MOZILLA_5_BROWSER_IDENTIFIER,
 				X11_WINDOW_SYSTEM,
 				LINUX,
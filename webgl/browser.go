@@ -0,0 +1,96 @@
+package webgl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chinese-room-solutions/fakebro/internal/family"
+)
+
+const (
+	browserChrome  = family.Chrome
+	browserFirefox = family.Firefox
+	browserSafari  = family.Safari
+)
+
+// browser returns the browser family and major version the options describe,
+// a major version of 0 meaning the latest one.
+func (o options) browser() (string, int, error) {
+	if o.BrowserVersion == "" {
+		return family.Of(o.Browser), 0, nil
+	}
+
+	name, v, err := family.Parse(o.Browser, o.BrowserVersion)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidBrowserVersion, o.BrowserVersion)
+	}
+	return name, v.Segments()[0], nil
+}
+
+// FirefoxData describes how Firefox sanitizes the WebGL context strings.
+type FirefoxData struct {
+	Vendor                       string                        `yaml:"vendor"`
+	Renderer                     string                        `yaml:"renderer"`
+	Version                      string                        `yaml:"version"`
+	ShadingLanguageVersion       string                        `yaml:"shading_language_version"`
+	WebGL2Version                string                        `yaml:"webgl2_version"`
+	WebGL2ShadingLanguageVersion string                        `yaml:"webgl2_shading_language_version"`
+	Templates                    map[string][]RendererTemplate `yaml:"templates"`
+	Classes                      []FirefoxClass                `yaml:"classes"`
+}
+
+// FirefoxClass maps the GPUs of a vendor whose model matches to the device Firefox reports for them.
+type FirefoxClass struct {
+	Vendor string `yaml:"vendor"`
+	Match  string `yaml:"match"`
+	Device string `yaml:"device"`
+
+	match *regexp.Regexp
+}
+
+// SafariData describes the renderer Safari reports.
+type SafariData struct {
+	Renderer string `yaml:"renderer"`
+}
+
+func (f *FirefoxData) compileClasses(errs *catalogErrors) {
+	for i := range f.Classes {
+		re, err := regexp.Compile(f.Classes[i].Match)
+		if err != nil {
+			errs.add(fmt.Errorf("class %q: %w", f.Classes[i].Device, err), "firefox", "classes", i, "match")
+			continue
+		}
+		f.Classes[i].match = re
+	}
+}
+
+func (f *FirefoxData) deviceFor(gpu *GPU) string {
+	for _, class := range f.Classes {
+		if class.Vendor == gpu.Vendor && class.match.MatchString(gpu.Model) {
+			return class.Device
+		}
+	}
+	return gpu.Device
+}
+
+// firefoxTemplateName returns the Firefox template used for the GPU's backend:
+// Direct3D GPUs are rendered through ANGLE, the others report the native driver.
+func firefoxTemplateName(gpu *GPU) string {
+	if strings.HasPrefix(gpu.Backend, "d3d") {
+		return "angle"
+	}
+	return "native"
+}
+
+// apply replaces the Chromium context strings of a profile with the ones Firefox reports.
+func (f *FirefoxData) apply(profile *Profile) {
+	profile.Vendor = f.Vendor
+	profile.Renderer = f.Renderer
+	profile.Version = f.Version
+	profile.ShadingLanguageVersion = f.ShadingLanguageVersion
+	if profile.WebGL2 != nil {
+		profile.WebGL2.Version = f.WebGL2Version
+		profile.WebGL2.ShadingLanguageVersion = f.WebGL2ShadingLanguageVersion
+	}
+}
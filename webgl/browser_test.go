@@ -0,0 +1,147 @@
+package webgl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// literalRenderer keeps the GPUs the catalog gives a literal renderer string.
+func literalRenderer(gpu GPU) int {
+	if gpu.Renderer != "" {
+		return gpu.Weight
+	}
+	return 0
+}
+
+func TestGenerateProfileWithBrowser(t *testing.T) {
+	tests := []struct {
+		name                   string
+		platform               string
+		platformVersion        string
+		opts                   []Option
+		expectedPattern        string
+		expectedVendor         string
+		expectedUnmaskedVendor string
+		expectedVersion        string
+		expectedError          error
+	}{
+		{
+			name:            "Firefox on Windows",
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			opts:            []Option{WithBrowser("Firefox", "130.0"), WithVendors("nvidia")},
+			expectedPattern: `^ANGLE \(NVIDIA, NVIDIA GeForce (GTX 980|GTX 480|8800 GTX) Direct3D11 vs_5_0 ps_5_0\), or similar$`,
+			expectedVendor:  "Mozilla",
+			expectedVersion: "WebGL 1.0",
+		},
+		{
+			name:            "Firefox 100 on Windows",
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			opts:            []Option{WithBrowser("Firefox", "100.0"), WithVendors("nvidia")},
+			expectedPattern: `^ANGLE \(NVIDIA, NVIDIA GeForce (GTX 980|GTX 480|8800 GTX) Direct3D11 vs_5_0 ps_5_0\)$`,
+			expectedVendor:  "Mozilla",
+			expectedVersion: "WebGL 1.0",
+		},
+		{
+			name:                   "Firefox on Linux",
+			platform:               "Linux",
+			platformVersion:        "6.5",
+			opts:                   []Option{WithBrowser("Firefox", "130.0"), WithVendors("intel")},
+			expectedPattern:        `^Intel\(R\) HD Graphics( 400)?, or similar$`,
+			expectedVendor:         "Mozilla",
+			expectedUnmaskedVendor: "^Intel",
+			expectedVersion:        "WebGL 1.0",
+		},
+		{
+			name:                   "Firefox on macOS",
+			platform:               "macOS",
+			platformVersion:        "14.0",
+			opts:                   []Option{WithBrowser("Firefox", "130.0")},
+			expectedPattern:        `^Apple M1, or similar$`,
+			expectedVendor:         "Mozilla",
+			expectedUnmaskedVendor: "^Apple$",
+			expectedVersion:        "WebGL 1.0",
+		},
+		{
+			name:                   "Safari on macOS",
+			platform:               "macOS",
+			platformVersion:        "14.0",
+			opts:                   []Option{WithBrowser("Safari", "17.4")},
+			expectedPattern:        `^Apple GPU$`,
+			expectedVendor:         "WebKit",
+			expectedUnmaskedVendor: `^Apple Inc\.$`,
+			expectedVersion:        "WebGL 1.0",
+		},
+		{
+			name:            "Safari on Windows",
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			opts:            []Option{WithBrowser("Safari", "17.4")},
+			expectedError:   ErrNoCompatibleRenderer,
+		},
+		{
+			name:            "invalid browser version",
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			opts:            []Option{WithBrowser("Firefox", "latest")},
+			expectedError:   ErrInvalidBrowserVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				profile, err := GenerateProfile(seed, tt.platform, tt.platformVersion, tt.opts...)
+				if tt.expectedError != nil {
+					require.ErrorIs(t, err, tt.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Regexp(t, tt.expectedPattern, profile.UnmaskedRenderer)
+				require.Equal(t, tt.expectedVendor, profile.Vendor)
+				require.Equal(t, tt.expectedVersion, profile.Version)
+				if tt.expectedUnmaskedVendor != "" {
+					require.Regexp(t, tt.expectedUnmaskedVendor, profile.UnmaskedVendor)
+				}
+				require.Nil(t, profile.WebGPU)
+			}
+		})
+	}
+}
+
+func TestLiteralRenderers(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		profile, err := GenerateProfile(seed, "Windows", "15.0.0", WithBrowser("Chrome", "130.0"), WithWeight(literalRenderer))
+		require.NoError(t, err)
+		require.Regexp(t, `^ANGLE \(`, profile.UnmaskedRenderer)
+
+		// Firefox only reports the renderers of GPU classes, never the literal Chrome ones.
+		_, err = GenerateProfile(seed, "Windows", "15.0.0", WithBrowser("Firefox", "130.0"), WithWeight(literalRenderer))
+		require.ErrorIs(t, err, ErrNoCompatibleRenderer)
+	}
+}
+
+func TestFirefoxDeviceFor(t *testing.T) {
+	tests := []struct {
+		gpu      GPU
+		expected string
+	}{
+		{GPU{Vendor: "nvidia", Model: "NVIDIA GeForce RTX 4090"}, "NVIDIA GeForce GTX 980"},
+		{GPU{Vendor: "nvidia", Model: "NVIDIA GeForce GTX 660"}, "NVIDIA GeForce GTX 480"},
+		{GPU{Vendor: "nvidia", Model: "NVIDIA GeForce 210"}, "NVIDIA GeForce 8800 GTX"},
+		{GPU{Vendor: "amd", Model: "AMD Radeon HD 5450"}, "Radeon HD 5850"},
+		{GPU{Vendor: "amd", Model: "AMD Radeon RX 6600"}, "Radeon R9 200 Series"},
+		{GPU{Vendor: "intel", Model: "Intel(R) HD Graphics 4000"}, "Intel(R) HD Graphics"},
+		{GPU{Vendor: "intel", Model: "Intel(R) Iris(R) Xe Graphics"}, "Intel(R) HD Graphics 400"},
+		{GPU{Vendor: "apple", Model: "Apple M3 Pro"}, "Apple M1"},
+		{GPU{Vendor: "qualcomm", Model: "Adreno(TM) 690", Device: "Qualcomm(R) Adreno(TM) 690 GPU"}, "Qualcomm(R) Adreno(TM) 690 GPU"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gpu.Model, func(t *testing.T) {
+			require.Equal(t, tt.expected, data.Firefox.deviceFor(&tt.gpu))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package webgl
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Generator generates renderers and profiles from its own renderer catalog.
+// The package level functions use a generator on the embedded catalog.
+type Generator struct {
+	data *RendererData
+}
+
+type catalogSource struct {
+	name string
+	read func() ([]byte, error)
+}
+
+type catalogOptions struct {
+	Sources        []catalogSource
+	WithoutDefault bool
+}
+
+type CatalogOption func(*catalogOptions)
+
+// WithCatalog merges the catalog read from r into the generator's catalog.
+// It uses the data.yml format and may only list the entries to add.
+func WithCatalog(r io.Reader) CatalogOption {
+	return func(o *catalogOptions) {
+		o.Sources = append(o.Sources, catalogSource{name: "catalog", read: func() ([]byte, error) {
+			return io.ReadAll(r)
+		}})
+	}
+}
+
+// WithCatalogFS merges the named catalog file of fsys into the generator's catalog.
+func WithCatalogFS(fsys fs.FS, name string) CatalogOption {
+	return func(o *catalogOptions) {
+		o.Sources = append(o.Sources, catalogSource{name: name, read: func() ([]byte, error) {
+			return fs.ReadFile(fsys, name)
+		}})
+	}
+}
+
+// WithoutDefaultCatalog starts from an empty catalog instead of the embedded one,
+// so that the given catalogs replace it.
+func WithoutDefaultCatalog() CatalogOption {
+	return func(o *catalogOptions) {
+		o.WithoutDefault = true
+	}
+}
+
+// NewGenerator creates a generator on the embedded catalog merged with the given ones, in order.
+// Merging adds the keys of a mapping and puts the entries of a list before the existing ones,
+// so that added parameter sets, adapters, Firefox classes and templates take precedence.
+// Problems are reported as name:line of the catalog that defines the offending value.
+func NewGenerator(opts ...CatalogOption) (*Generator, error) {
+	o := catalogOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if !o.WithoutDefault {
+		o.Sources = slices.Insert(o.Sources, 0, catalogSource{name: "data.yml", read: func() ([]byte, error) {
+			return dataFile.ReadFile("data.yml")
+		}})
+	}
+
+	var root *yaml.Node
+	sources := map[*yaml.Node]string{}
+	for _, source := range o.Sources {
+		b, err := source.read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source.name, err)
+		}
+
+		catalog, err := parseCatalog(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.name, err)
+		}
+		recordSource(sources, catalog, source.name)
+
+		if root == nil {
+			root = catalog
+		} else {
+			root = mergeCatalogs(root, catalog)
+		}
+	}
+
+	if root == nil {
+		root, _ = parseCatalog(nil)
+	}
+
+	d, err := newRendererData(root, sources)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{data: &d}, nil
+}
+
+// mergeCatalogs merges src into dst and returns the result. Mappings are merged key by key,
+// the entries of src lists come first and any other src value replaces the dst one.
+func mergeCatalogs(dst, src *yaml.Node) *yaml.Node {
+	switch {
+	case dst.Kind == yaml.DocumentNode && src.Kind == yaml.DocumentNode:
+		dst.Content[0] = mergeCatalogs(dst.Content[0], src.Content[0])
+		return dst
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			j := mappingKeyIndex(dst, key.Value)
+			if j < 0 {
+				dst.Content = append(dst.Content, key, value)
+				continue
+			}
+			dst.Content[j+1] = mergeCatalogs(dst.Content[j+1], value)
+		}
+		return dst
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		dst.Content = append(slices.Clone(src.Content), dst.Content...)
+		return dst
+	default:
+		return src
+	}
+}
+
+// mappingKeyIndex returns the index of the key in the mapping node's content, or -1.
+func mappingKeyIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
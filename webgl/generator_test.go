@@ -0,0 +1,173 @@
+package webgl
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const telemetryCatalog = `
+Windows:
+  0.0.0:
+    - {vendor: moore, model: 'MTT S80', device_id: '0x00000106', backend: d3d11, tier: mid, discrete: true, release_year: 2022, tags: [telemetry]}
+vendors:
+  moore: Moore Threads
+parameters:
+  - name: moore threads
+    match: '^ANGLE \(Moore Threads, '
+    vendor: WebKit
+    renderer: WebKit WebGL
+    version: WebGL 1.0 (OpenGL ES 2.0 Chromium)
+    shading_language_version: WebGL GLSL ES 1.0 (OpenGL ES GLSL ES 1.0 Chromium)
+    max_texture_size: 8192
+    max_viewport_dims: [8192, 8192]
+`
+
+const linuxCatalog = `
+Linux:
+  0.0.0:
+    - {vendor: intel, model: 'Intel(R) Arc(tm) A770 Graphics (DG2)', backend: opengl, api: '4.6', tier: mid}
+vendors:
+  intel: Intel
+templates:
+  opengl:
+    - template: 'ANGLE ({{.Vendor}}, {{.Device}}, OpenGL {{.API}})'
+`
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            []CatalogOption
+		platform        string
+		platformVersion string
+		genOpts         []Option
+		expected        string
+		expectedError   error
+	}{
+		{
+			name:            "merged GPU",
+			opts:            []CatalogOption{WithCatalog(strings.NewReader(telemetryCatalog))},
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			genOpts:         []Option{WithTags("telemetry")},
+			expected:        "ANGLE (Moore Threads, MTT S80 (0x00000106) Direct3D11 vs_5_0 ps_5_0, D3D11)",
+		},
+		{
+			name:            "merged GPU with an older Chrome",
+			opts:            []CatalogOption{WithCatalog(strings.NewReader(telemetryCatalog))},
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			genOpts:         []Option{WithTags("telemetry"), WithBrowser("Chrome", "100.0.0.0")},
+			expected:        "ANGLE (Moore Threads, MTT S80 Direct3D11 vs_5_0 ps_5_0, D3D11)",
+		},
+		{
+			name:            "embedded GPUs kept",
+			opts:            []CatalogOption{WithCatalog(strings.NewReader(telemetryCatalog))},
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			genOpts:         []Option{WithVendors("nvidia"), WithRendererMatch(regexp.MustCompile(`RTX 4090`))},
+			expected:        "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090 (0x00002684) Direct3D11 vs_5_0 ps_5_0, D3D11)",
+		},
+		{
+			name: "overridden template",
+			opts: []CatalogOption{WithCatalog(strings.NewReader(`
+templates:
+  d3d11:
+    - since: 106
+      template: 'ANGLE ({{.Vendor}}, {{.Device}} Direct3D11)'
+`))},
+			platform:        "Windows",
+			platformVersion: "15.0.0",
+			genOpts:         []Option{WithVendors("nvidia"), WithRendererMatch(regexp.MustCompile(`RTX 4090`))},
+			expected:        "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090 Direct3D11)",
+		},
+		{
+			name: "replaced catalog",
+			opts: []CatalogOption{
+				WithoutDefaultCatalog(),
+				WithCatalogFS(fstest.MapFS{"gpus.yml": {Data: []byte(linuxCatalog)}}, "gpus.yml"),
+			},
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			expected:        "ANGLE (Intel, Intel(R) Arc(tm) A770 Graphics (DG2), OpenGL 4.6)",
+		},
+		{
+			name:            "replaced catalog without platform",
+			opts:            []CatalogOption{WithoutDefaultCatalog(), WithCatalog(strings.NewReader(linuxCatalog))},
+			platform:        "macOS",
+			platformVersion: "14.0",
+			expectedError:   ErrNoCompatibleRenderer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGenerator(tt.opts...)
+			require.NoError(t, err)
+
+			renderer, err := g.GenerateRenderer(1, tt.platform, tt.platformVersion, tt.genOpts...)
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, renderer)
+		})
+	}
+}
+
+func TestNewGeneratorProfile(t *testing.T) {
+	g, err := NewGenerator(WithCatalog(strings.NewReader(telemetryCatalog)))
+	require.NoError(t, err)
+
+	profile, err := g.GenerateProfile(1, "Windows", "15.0.0", WithTags("telemetry"))
+	require.NoError(t, err)
+	require.Equal(t, 8192, profile.MaxTextureSize)
+	require.Equal(t, "Google Inc. (Moore Threads)", profile.UnmaskedVendor)
+
+	// The package level functions keep using the embedded catalog.
+	_, err = GenerateRenderer(1, "Windows", "15.0.0", WithTags("telemetry"))
+	require.ErrorIs(t, err, ErrNoCompatibleRenderer)
+}
+
+func TestNewGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []CatalogOption
+		expectedError string
+	}{
+		{
+			name:          "unknown field",
+			opts:          []CatalogOption{WithCatalog(strings.NewReader("Windows:\n  0.0.0:\n    - {vendor: nvidia, modle: 'RTX'}\n"))},
+			expectedError: "catalog: invalid renderer catalog: yaml: unmarshal errors:\n  line 3: field modle not found",
+		},
+		{
+			name:          "invalid merged entry",
+			opts:          []CatalogOption{WithCatalog(strings.NewReader("Windows:\n  0.0.0:\n    - {vendor: moore, model: 'MTT S80', backend: d3d11, tier: mid}\n"))},
+			expectedError: `catalog:3: gpu "MTT S80": unknown vendor "moore"`,
+		},
+		{
+			name: "invalid entry of named catalog",
+			opts: []CatalogOption{WithCatalogFS(fstest.MapFS{"gpus.yml": {Data: []byte(
+				"Windows:\n  0.0.0:\n    - {vendor: nvidia, model: 'RTX', backend: glide, tier: mid}\n",
+			)}}, "gpus.yml")},
+			expectedError: `gpus.yml:3: gpu "RTX": no template for backend "glide"`,
+		},
+		{
+			name:          "missing file",
+			opts:          []CatalogOption{WithCatalogFS(fstest.MapFS{}, "gpus.yml")},
+			expectedError: "failed to read gpus.yml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator(tt.opts...)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
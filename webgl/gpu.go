@@ -0,0 +1,361 @@
+package webgl
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/go-version"
+)
+
+// GPU describes a graphics adapter in the renderer catalog.
+type GPU struct {
+	Vendor      string   `yaml:"vendor"`
+	Model       string   `yaml:"model"`
+	Device      string   `yaml:"device"`
+	ANGLEVendor string   `yaml:"angle_vendor"`
+	DeviceID    string   `yaml:"device_id"`
+	Backend     string   `yaml:"backend"`
+	API         string   `yaml:"api"`
+	Driver      string   `yaml:"driver"`
+	ShaderModel string   `yaml:"shader_model"`
+	Tier        string   `yaml:"tier"`
+	Discrete    bool     `yaml:"discrete"`
+	ReleaseYear int      `yaml:"release_year"`
+	IsVirtual   bool     `yaml:"is_virtual"`
+	Tags        []string `yaml:"tags"`
+	Weight      int      `yaml:"weight"`
+	// Renderer is the UNMASKED_RENDERER_WEBGL value, rendered from the backend template
+	// for the requested browser version unless the catalog provides it verbatim.
+	Renderer string `yaml:"renderer"`
+
+	renderers        []versionedRenderer
+	firefoxRenderers []versionedRenderer
+	// platformVersions is the platform version range of the catalog key the GPU is listed under.
+	platformVersions version.Constraints
+}
+
+// RendererTemplate formats renderer strings starting with a browser major version.
+type RendererTemplate struct {
+	Since    int    `yaml:"since"`
+	Template string `yaml:"template"`
+}
+
+type rendererTemplateData struct {
+	Vendor      string
+	Device      string
+	DeviceID    string
+	API         string
+	Driver      string
+	ShaderModel string
+}
+
+type versionedRenderer struct {
+	since    int
+	renderer string
+}
+
+type versionedTemplate struct {
+	since int
+	tmpl  *template.Template
+}
+
+// platformGPUs returns the GPU lists keyed by their section name in data.yml.
+func (d *RendererData) platformGPUs() map[string]map[string][]GPU {
+	return map[string]map[string][]GPU{
+		"macOS":       d.MacOS,
+		"Linux":       d.Linux,
+		"Windows":     d.Windows,
+		"Android":     d.Android,
+		"iOS":         d.IOS,
+		"Chrome OS":   d.ChromeOS,
+		"iPadOS":      d.IPadOS,
+		"Windows arm": d.WindowsARM,
+		"Linux arm":   d.LinuxARM,
+	}
+}
+
+func compileTemplates(templates map[string][]RendererTemplate, errs *catalogErrors, path ...any) map[string][]versionedTemplate {
+	compiled := make(map[string][]versionedTemplate, len(templates))
+	for _, name := range slices.Sorted(maps.Keys(templates)) {
+		versioned := templates[name]
+		for i, t := range versioned {
+			tmpl, err := template.New(name).Option("missingkey=error").Parse(t.Template)
+			if err != nil {
+				errs.add(fmt.Errorf("template %q since %d: %w", name, t.Since, err), append(path, name, i, "template")...)
+				continue
+			}
+			compiled[name] = append(compiled[name], versionedTemplate{since: t.Since, tmpl: tmpl})
+		}
+		// The first template listed for a version wins, so that merged catalogs can override it.
+		slices.SortStableFunc(compiled[name], func(a, b versionedTemplate) int {
+			return a.since - b.since
+		})
+		compiled[name] = slices.CompactFunc(compiled[name], func(a, b versionedTemplate) bool {
+			return a.since == b.since
+		})
+	}
+	return compiled
+}
+
+func executeTemplates(templates []versionedTemplate, data rendererTemplateData) ([]versionedRenderer, error) {
+	renderers := make([]versionedRenderer, 0, len(templates))
+	for _, t := range templates {
+		var b strings.Builder
+		if err := t.tmpl.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		renderers = append(renderers, versionedRenderer{since: t.since, renderer: b.String()})
+	}
+	return renderers, nil
+}
+
+// renderGPUs fills in defaults and renders the renderer strings of every catalog entry
+// for each browser version range the templates cover.
+func (d *RendererData) renderGPUs(errs *catalogErrors) {
+	templates := compileTemplates(d.Templates, errs, "templates")
+	firefoxTemplates := compileTemplates(d.Firefox.Templates, errs, "firefox", "templates")
+	d.Firefox.compileClasses(errs)
+
+	platforms := d.platformGPUs()
+	for _, platform := range slices.Sorted(maps.Keys(platforms)) {
+		versioned := platforms[platform]
+		for _, key := range slices.Sorted(maps.Keys(versioned)) {
+			gpus := versioned[key]
+			platformVersions, err := parseVersionRange(key)
+			if err != nil {
+				errs.add(err, platform, key)
+			}
+			for i := range gpus {
+				gpus[i].platformVersions = platformVersions
+				for _, err := range validateGPU(&gpus[i]) {
+					errs.add(fmt.Errorf("gpu %q: %w", gpus[i].Model, err), platform, key, i)
+				}
+				if err := d.renderGPU(&gpus[i], templates, firefoxTemplates); err != nil {
+					errs.add(fmt.Errorf("gpu %q: %w", gpus[i].Model, err), platform, key, i)
+				}
+			}
+		}
+	}
+}
+
+// validateGPU checks the catalog fields that are not needed to render the GPU.
+func validateGPU(gpu *GPU) []error {
+	var errs []error
+	if gpu.Vendor == "" {
+		errs = append(errs, fmt.Errorf("missing vendor"))
+	}
+	if gpu.Model == "" {
+		errs = append(errs, fmt.Errorf("missing model"))
+	}
+	if !slices.Contains([]string{"low", "mid", "high"}, gpu.Tier) {
+		errs = append(errs, fmt.Errorf("invalid tier %q", gpu.Tier))
+	}
+	if gpu.Weight < 0 {
+		errs = append(errs, fmt.Errorf("negative weight %d", gpu.Weight))
+	}
+	return errs
+}
+
+func (d *RendererData) renderGPU(gpu *GPU, templates, firefoxTemplates map[string][]versionedTemplate) error {
+	if gpu.Device == "" {
+		gpu.Device = gpu.Model
+	}
+	if gpu.ShaderModel == "" {
+		gpu.ShaderModel = "5_0"
+	}
+	if gpu.Weight == 0 {
+		gpu.Weight = 1
+	}
+	if gpu.Renderer != "" {
+		// Literal renderers are what Chrome reports; Firefox never exposes them, so these GPUs
+		// are not available to it.
+		gpu.renderers = []versionedRenderer{{renderer: gpu.Renderer}}
+		return nil
+	}
+
+	vendor := d.vendorName(gpu)
+	if vendor == "" {
+		return fmt.Errorf("unknown vendor %q", gpu.Vendor)
+	}
+
+	tmpls, ok := templates[gpu.Backend]
+	if !ok {
+		return fmt.Errorf("no template for backend %q", gpu.Backend)
+	}
+
+	var err error
+	gpu.renderers, err = executeTemplates(tmpls, rendererTemplateData{
+		Vendor:      vendor,
+		Device:      gpu.Device,
+		DeviceID:    gpu.DeviceID,
+		API:         gpu.API,
+		Driver:      gpu.Driver,
+		ShaderModel: gpu.ShaderModel,
+	})
+	if err != nil {
+		return err
+	}
+
+	gpu.firefoxRenderers, err = executeTemplates(firefoxTemplates[firefoxTemplateName(gpu)], rendererTemplateData{
+		Vendor: vendor,
+		Device: d.Firefox.deviceFor(gpu),
+	})
+	return err
+}
+
+// vendorName returns the vendor as the driver reports it.
+func (d *RendererData) vendorName(gpu *GPU) string {
+	if gpu.ANGLEVendor != "" {
+		return gpu.ANGLEVendor
+	}
+	return d.Vendors[gpu.Vendor]
+}
+
+// renderer returns the renderer string the browser major version reports for the GPU,
+// 0 meaning the latest version. It reports false if that browser version cannot report the GPU.
+func (d *RendererData) renderer(gpu *GPU, browser string, major int) (string, bool) {
+	switch browser {
+	case browserSafari:
+		return d.Safari.Renderer, gpu.Vendor == "apple"
+	case browserFirefox:
+		return pickRenderer(gpu.firefoxRenderers, major)
+	default:
+		return pickRenderer(gpu.renderers, major)
+	}
+}
+
+func pickRenderer(renderers []versionedRenderer, major int) (string, bool) {
+	if len(renderers) == 0 {
+		return "", false
+	}
+	if major == 0 {
+		return renderers[len(renderers)-1].renderer, true
+	}
+	for i := len(renderers) - 1; i >= 0; i-- {
+		if renderers[i].since <= major {
+			return renderers[i].renderer, true
+		}
+	}
+	return "", false
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
+
+// WithVendors keeps GPUs from the given vendors, e.g. "nvidia" or "intel".
+func WithVendors(vendors ...string) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return containsFold(vendors, gpu.Vendor)
+		})
+	}
+}
+
+// WithTiers keeps GPUs of the given performance tiers: "low", "mid" or "high".
+func WithTiers(tiers ...string) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return containsFold(tiers, gpu.Tier)
+		})
+	}
+}
+
+// WithBackends keeps GPUs rendered through the given ANGLE backends, e.g. "d3d11" or "vulkan".
+func WithBackends(backends ...string) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return containsFold(backends, gpu.Backend)
+		})
+	}
+}
+
+// WithoutVendors drops GPUs from the given vendors.
+func WithoutVendors(vendors ...string) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return !containsFold(vendors, gpu.Vendor)
+		})
+	}
+}
+
+// WithTags keeps GPUs carrying at least one of the given tags, e.g. "rare".
+func WithTags(tags ...string) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return slices.ContainsFunc(gpu.Tags, func(tag string) bool {
+				return containsFold(tags, tag)
+			})
+		})
+	}
+}
+
+// WithoutTags drops GPUs carrying any of the given tags, e.g. "software" or "vm".
+func WithoutTags(tags ...string) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return !slices.ContainsFunc(gpu.Tags, func(tag string) bool {
+				return containsFold(tags, tag)
+			})
+		})
+	}
+}
+
+// WithRendererMatch keeps GPUs whose reported renderer matches the expression.
+func WithRendererMatch(re *regexp.Regexp) Option {
+	return func(o *options) {
+		o.RendererFilters = append(o.RendererFilters, re.MatchString)
+	}
+}
+
+// WithoutRendererMatch drops GPUs whose reported renderer matches the expression,
+// e.g. regexp.MustCompile("llvmpipe|Parallels").
+func WithoutRendererMatch(re *regexp.Regexp) Option {
+	return func(o *options) {
+		o.RendererFilters = append(o.RendererFilters, func(renderer string) bool {
+			return !re.MatchString(renderer)
+		})
+	}
+}
+
+// WithWeight overrides the selection weight of the catalog entries. The function receives
+// each compatible GPU with its catalog weight and returns the weight to use, 0 dropping the GPU.
+func WithWeight(weight func(gpu GPU) int) Option {
+	return func(o *options) {
+		o.Weight = weight
+	}
+}
+
+// WithDiscrete keeps dedicated GPUs only.
+func WithDiscrete() Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return gpu.Discrete
+		})
+	}
+}
+
+// WithoutVirtual drops software renderers and virtual machine adapters.
+func WithoutVirtual() Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return !gpu.IsVirtual
+		})
+	}
+}
+
+// WithReleasedSince keeps GPUs released in or after the given year.
+// GPUs with an unknown release year are kept.
+func WithReleasedSince(year int) Option {
+	return func(o *options) {
+		o.Filters = append(o.Filters, func(gpu *GPU) bool {
+			return gpu.ReleaseYear == 0 || gpu.ReleaseYear >= year
+		})
+	}
+}
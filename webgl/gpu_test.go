@@ -0,0 +1,338 @@
+package webgl
+
+import (
+	"regexp"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGPUWithFilters(t *testing.T) {
+	tests := []struct {
+		name            string
+		platform        string
+		platformVersion string
+		opts            []Option
+		check           func(t *testing.T, gpu GPU)
+		expectedError   error
+	}{
+		{
+			name:            "vendor",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithVendors("NVIDIA")},
+			check: func(t *testing.T, gpu GPU) {
+				require.Equal(t, "nvidia", gpu.Vendor)
+			},
+		},
+		{
+			name:            "discrete",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithDiscrete(), WithTiers("high")},
+			check: func(t *testing.T, gpu GPU) {
+				require.True(t, gpu.Discrete)
+				require.Equal(t, "high", gpu.Tier)
+			},
+		},
+		{
+			name:            "without virtual",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithoutVirtual()},
+			check: func(t *testing.T, gpu GPU) {
+				require.False(t, gpu.IsVirtual)
+				require.NotContains(t, gpu.Renderer, "llvmpipe")
+			},
+		},
+		{
+			name:            "backend",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithBackends("vulkan")},
+			check: func(t *testing.T, gpu GPU) {
+				require.Equal(t, "vulkan", gpu.Backend)
+				require.Contains(t, gpu.Renderer, "Vulkan 1.3")
+			},
+		},
+		{
+			name:            "released since",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithReleasedSince(2022), WithoutVirtual()},
+			check: func(t *testing.T, gpu GPU) {
+				require.GreaterOrEqual(t, gpu.ReleaseYear, 2022)
+			},
+		},
+		{
+			name:            "without vendors",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithoutVendors("nvidia", "AMD", "intel")},
+			check: func(t *testing.T, gpu GPU) {
+				require.NotContains(t, []string{"nvidia", "amd", "intel"}, gpu.Vendor)
+			},
+		},
+		{
+			name:            "tags",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithTags("rare")},
+			check: func(t *testing.T, gpu GPU) {
+				require.Contains(t, gpu.Tags, "rare")
+			},
+		},
+		{
+			name:            "without tags",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithoutTags("vm", "software")},
+			check: func(t *testing.T, gpu GPU) {
+				require.NotContains(t, gpu.Tags, "vm")
+				require.NotContains(t, gpu.Tags, "software")
+				require.NotContains(t, gpu.Renderer, "Parallels")
+			},
+		},
+		{
+			name:            "renderer match",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithRendererMatch(regexp.MustCompile(`Direct3D9`))},
+			check: func(t *testing.T, gpu GPU) {
+				require.Equal(t, "d3d9", gpu.Backend)
+			},
+		},
+		{
+			name:            "without renderer match",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithoutRendererMatch(regexp.MustCompile(`llvmpipe|Mesa`))},
+			check: func(t *testing.T, gpu GPU) {
+				require.NotRegexp(t, `llvmpipe|Mesa`, gpu.Renderer)
+			},
+		},
+		{
+			name:            "weight",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts: []Option{WithWeight(func(gpu GPU) int {
+				if gpu.Model == "NVIDIA GeForce RTX 4090" {
+					return gpu.Weight
+				}
+				return 0
+			})},
+			check: func(t *testing.T, gpu GPU) {
+				require.Equal(t, "NVIDIA GeForce RTX 4090", gpu.Model)
+			},
+		},
+		{
+			name:            "no match",
+			platform:        "macOS",
+			platformVersion: "14.4",
+			opts:            []Option{WithVendors("nvidia")},
+			expectedError:   ErrNoCompatibleRenderer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				gpu, err := GenerateGPU(seed, tt.platform, tt.platformVersion, tt.opts...)
+				if tt.expectedError != nil {
+					require.ErrorIs(t, err, tt.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				tt.check(t, gpu)
+			}
+		})
+	}
+}
+
+func TestRenderGPU(t *testing.T) {
+	d := RendererData{Vendors: map[string]string{"nvidia": "NVIDIA"}}
+	templates := map[string][]versionedTemplate{
+		"d3d11": {
+			{tmpl: template.Must(template.New("d3d11").Parse(
+				"ANGLE ({{.Device}} Direct3D11 vs_{{.ShaderModel}} ps_{{.ShaderModel}})",
+			))},
+			{since: 106, tmpl: template.Must(template.New("d3d11").Parse(
+				"ANGLE ({{.Vendor}}, {{.Device}}{{with .DeviceID}} ({{.}}){{end}} Direct3D11 vs_{{.ShaderModel}} ps_{{.ShaderModel}}, D3D11)",
+			))},
+		},
+		"vulkan": {
+			{since: 97, tmpl: template.Must(template.New("vulkan").Parse(
+				"ANGLE ({{.Vendor}}, Vulkan {{.API}} ({{.Device}}), {{.Driver}})",
+			))},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		gpu           GPU
+		chrome        int
+		expected      string
+		expectedOK    bool
+		expectedError bool
+	}{
+		{
+			name:       "defaults",
+			gpu:        GPU{Vendor: "nvidia", Model: "NVIDIA GeForce RTX 4090", DeviceID: "0x00002684", Backend: "d3d11"},
+			expected:   "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090 (0x00002684) Direct3D11 vs_5_0 ps_5_0, D3D11)",
+			expectedOK: true,
+		},
+		{
+			name:       "overrides",
+			gpu:        GPU{Vendor: "nvidia", ANGLEVendor: "NVIDIA Corporation", Model: "GeForce 210", Device: "NVIDIA GeForce 210", ShaderModel: "4_1", Backend: "d3d11"},
+			chrome:     120,
+			expected:   "ANGLE (NVIDIA Corporation, NVIDIA GeForce 210 Direct3D11 vs_4_1 ps_4_1, D3D11)",
+			expectedOK: true,
+		},
+		{
+			name:       "older Chrome format",
+			gpu:        GPU{Vendor: "nvidia", Model: "NVIDIA GeForce GTX 1060", DeviceID: "0x00001C03", Backend: "d3d11"},
+			chrome:     90,
+			expected:   "ANGLE (NVIDIA GeForce GTX 1060 Direct3D11 vs_5_0 ps_5_0)",
+			expectedOK: true,
+		},
+		{
+			name:   "backend not used by Chrome version",
+			gpu:    GPU{Vendor: "nvidia", Model: "NVIDIA GeForce GTX 1060", API: "1.3.277", Driver: "NVIDIA", Backend: "vulkan"},
+			chrome: 96,
+		},
+		{
+			name:       "literal renderer",
+			gpu:        GPU{Vendor: "apple", Model: "Apple GPU", Backend: "metal", Renderer: "Apple GPU"},
+			chrome:     90,
+			expected:   "Apple GPU",
+			expectedOK: true,
+		},
+		{
+			name:          "unknown vendor",
+			gpu:           GPU{Vendor: "3dfx", Model: "Voodoo3", Backend: "d3d11"},
+			expectedError: true,
+		},
+		{
+			name:          "unknown backend",
+			gpu:           GPU{Vendor: "nvidia", Model: "NVIDIA GeForce RTX 4090", Backend: "glide"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpu := tt.gpu
+			err := d.renderGPU(&gpu, templates, nil)
+			if tt.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, gpu.Weight)
+
+			renderer, ok := pickRenderer(gpu.renderers, tt.chrome)
+			require.Equal(t, tt.expectedOK, ok)
+			require.Equal(t, tt.expected, renderer)
+		})
+	}
+}
+
+func TestGenerateRendererWithChromeVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		platform        string
+		platformVersion string
+		opts            []Option
+		expectedPattern string
+		expectedError   error
+	}{
+		{
+			name:            "macOS OpenGL backend",
+			platform:        "macOS",
+			platformVersion: "14.4",
+			opts:            []Option{WithBrowser("Chrome", "114.0.0.0")},
+			expectedPattern: `^ANGLE \(Apple, Apple M\d[^,]*, OpenGL 4\.1\)$`,
+		},
+		{
+			name:            "macOS Metal backend",
+			platform:        "macOS",
+			platformVersion: "14.4",
+			opts:            []Option{WithBrowser("Chrome", "129.0.0.0")},
+			expectedPattern: `^ANGLE \(Apple, ANGLE Metal Renderer: Apple M\d[^,]*, Unspecified Version\)$`,
+		},
+		{
+			name:            "Windows legacy format",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithBrowser("Chrome", "90.0.4430.212"), WithVendors("nvidia"), WithBackends("d3d11")},
+			expectedPattern: `^ANGLE \(NVIDIA [^,]+ Direct3D11 vs_\d_\d ps_\d_\d\)$`,
+		},
+		{
+			name:            "Windows Chrome 92 format",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithBrowser("Chrome", "92.0.4515.107"), WithVendors("nvidia"), WithBackends("d3d11")},
+			expectedPattern: `^ANGLE \(NVIDIA, NVIDIA [^,]+ Direct3D11 vs_\d_\d ps_\d_\d\)$`,
+		},
+		{
+			name:            "Windows current format",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			opts:            []Option{WithBrowser("Chrome", "129.0.0.0"), WithVendors("nvidia"), WithBackends("d3d11")},
+			expectedPattern: `^ANGLE \(NVIDIA, NVIDIA [^,]+ Direct3D11 vs_\d_\d ps_\d_\d, D3D11(-[\d.]+)?\)$`,
+		},
+		{
+			name:            "Linux before ANGLE",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithBrowser("Chrome", "96.0.0.0"), WithVendors("intel")},
+			expectedPattern: `^Mesa [^,]+$`,
+		},
+		{
+			name:            "Linux Vulkan before ANGLE",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithBrowser("Chrome", "96.0.0.0"), WithBackends("vulkan")},
+			expectedError:   ErrNoCompatibleRenderer,
+		},
+		{
+			name:            "invalid version",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithBrowser("Chrome", "latest")},
+			expectedError:   ErrInvalidBrowserVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < 50; seed++ {
+				renderer, err := GenerateRenderer(seed, tt.platform, tt.platformVersion, tt.opts...)
+				if tt.expectedError != nil {
+					require.ErrorIs(t, err, tt.expectedError)
+					return
+				}
+				require.NoError(t, err)
+				require.Regexp(t, tt.expectedPattern, renderer)
+			}
+		})
+	}
+}
+
+func TestGPUCatalog(t *testing.T) {
+	for _, versioned := range data.platformGPUs() {
+		for _, gpus := range versioned {
+			for _, gpu := range gpus {
+				require.NotEmpty(t, gpu.Vendor, gpu.Model)
+				require.NotEmpty(t, gpu.Model)
+				require.Contains(t, []string{"low", "mid", "high"}, gpu.Tier, gpu.Model)
+				require.Contains(t, data.Templates, gpu.Backend, gpu.Model)
+				require.Positive(t, gpu.Weight, gpu.Model)
+
+				_, ok := data.renderer(&gpu, browserChrome, 0)
+				require.True(t, ok, gpu.Model)
+			}
+		}
+	}
+}
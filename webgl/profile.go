@@ -0,0 +1,208 @@
+package webgl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile describes the values a WebGL context reports for a generated renderer.
+type Profile struct {
+	Vendor                 string
+	Renderer               string
+	UnmaskedVendor         string
+	UnmaskedRenderer       string
+	Version                string
+	ShadingLanguageVersion string
+	MaxTextureSize         int
+	MaxViewportDims        [2]int
+	Extensions             []string
+	PrecisionFormats       PrecisionFormats
+	GPU                    GPU
+
+	// WebGL2 is nil when the renderer does not support WebGL 2 contexts.
+	WebGL2 *WebGL2Parameters
+	// WebGPU is nil when navigator.gpu does not provide an adapter for the renderer and browser.
+	WebGPU *WebGPUProfile
+}
+
+// WebGL2Parameters holds the values a WebGL 2 context reports in addition to the WebGL 1 ones.
+type WebGL2Parameters struct {
+	Version                  string   `yaml:"version"`
+	ShadingLanguageVersion   string   `yaml:"shading_language_version"`
+	Max3DTextureSize         int      `yaml:"max_3d_texture_size"`
+	MaxArrayTextureLayers    int      `yaml:"max_array_texture_layers"`
+	MaxSamples               int      `yaml:"max_samples"`
+	MaxColorAttachments      int      `yaml:"max_color_attachments"`
+	MaxDrawBuffers           int      `yaml:"max_draw_buffers"`
+	MaxUniformBufferBindings int      `yaml:"max_uniform_buffer_bindings"`
+	MaxUniformBlockSize      int      `yaml:"max_uniform_block_size"`
+	Extensions               []string `yaml:"extensions"`
+}
+
+// ShaderPrecisionFormat is the result of getShaderPrecisionFormat.
+type ShaderPrecisionFormat struct {
+	RangeMin  int
+	RangeMax  int
+	Precision int
+}
+
+// UnmarshalYAML decodes a [rangeMin, rangeMax, precision] sequence.
+func (f *ShaderPrecisionFormat) UnmarshalYAML(value *yaml.Node) error {
+	var v [3]int
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	f.RangeMin, f.RangeMax, f.Precision = v[0], v[1], v[2]
+	return nil
+}
+
+type ShaderPrecisionFormats struct {
+	LowFloat    ShaderPrecisionFormat `yaml:"low_float"`
+	MediumFloat ShaderPrecisionFormat `yaml:"medium_float"`
+	HighFloat   ShaderPrecisionFormat `yaml:"high_float"`
+	LowInt      ShaderPrecisionFormat `yaml:"low_int"`
+	MediumInt   ShaderPrecisionFormat `yaml:"medium_int"`
+	HighInt     ShaderPrecisionFormat `yaml:"high_int"`
+}
+
+type PrecisionFormats struct {
+	Vertex   ShaderPrecisionFormats `yaml:"vertex"`
+	Fragment ShaderPrecisionFormats `yaml:"fragment"`
+}
+
+// Parameters is a set of context parameters shared by the renderers it matches.
+type Parameters struct {
+	Name                   string            `yaml:"name"`
+	Match                  string            `yaml:"match"`
+	Vendor                 string            `yaml:"vendor"`
+	Renderer               string            `yaml:"renderer"`
+	UnmaskedVendor         string            `yaml:"unmasked_vendor"`
+	Version                string            `yaml:"version"`
+	ShadingLanguageVersion string            `yaml:"shading_language_version"`
+	MaxTextureSize         int               `yaml:"max_texture_size"`
+	MaxViewportDims        [2]int            `yaml:"max_viewport_dims"`
+	Extensions             []string          `yaml:"extensions"`
+	PrecisionFormats       PrecisionFormats  `yaml:"precision_formats"`
+	WebGL2                 *WebGL2Parameters `yaml:"webgl2"`
+	WebGPU                 *WebGPUParameters `yaml:"webgpu"`
+
+	match *regexp.Regexp
+}
+
+func (d *RendererData) compileParameters(errs *catalogErrors) {
+	for i := range d.Parameters {
+		re, err := regexp.Compile(d.Parameters[i].Match)
+		if err != nil {
+			errs.add(fmt.Errorf("parameters %q: %w", d.Parameters[i].Name, err), "parameters", i, "match")
+			continue
+		}
+		d.Parameters[i].match = re
+	}
+}
+
+func (d *RendererData) parametersFor(renderer string) (*Parameters, bool) {
+	for i := range d.Parameters {
+		if d.Parameters[i].match.MatchString(renderer) {
+			return &d.Parameters[i], true
+		}
+	}
+	return nil, false
+}
+
+// GenerateProfile generates a renderer like GenerateRenderer and returns it together with
+// the rest of the WebGL context parameters reported alongside it.
+func GenerateProfile(seed int64, platform string, platformVersion string, opts ...Option) (*Profile, error) {
+	return defaultGenerator.GenerateProfile(seed, platform, platformVersion, opts...)
+}
+
+// GenerateProfile generates a WebGL profile from the generator's catalog.
+func (g *Generator) GenerateProfile(seed int64, platform string, platformVersion string, opts ...Option) (*Profile, error) {
+	o := newOptions(opts)
+
+	gpu, err := g.data.generateGPU(seed, platform, platformVersion, o)
+	if err != nil {
+		return nil, err
+	}
+	browser, _, err := o.browser()
+	if err != nil {
+		return nil, err
+	}
+	renderer := gpu.Renderer
+	// Parameters and adapters are described in terms of the latest Chrome renderer format,
+	// except for Safari which has parameter sets of its own.
+	latest, _ := g.data.renderer(gpu, browserChrome, 0)
+	match := latest
+	if browser == browserSafari {
+		match = renderer
+	}
+
+	params, ok := g.data.parametersFor(match)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParameters, match)
+	}
+
+	// Firefox reports the vendor of the driver rather than the one Chrome is captured with.
+	var unmaskedVendor string
+	if browser != browserFirefox {
+		unmaskedVendor = params.UnmaskedVendor
+	}
+	if unmaskedVendor == "" {
+		unmaskedVendor = angleUnmaskedVendor(renderer)
+	}
+	if unmaskedVendor == "" {
+		unmaskedVendor = g.data.vendorName(gpu)
+	}
+
+	profile := &Profile{
+		Vendor:                 params.Vendor,
+		Renderer:               params.Renderer,
+		UnmaskedVendor:         unmaskedVendor,
+		UnmaskedRenderer:       renderer,
+		Version:                params.Version,
+		ShadingLanguageVersion: params.ShadingLanguageVersion,
+		MaxTextureSize:         params.MaxTextureSize,
+		MaxViewportDims:        params.MaxViewportDims,
+		Extensions:             append([]string(nil), params.Extensions...),
+		PrecisionFormats:       params.PrecisionFormats,
+		GPU:                    *gpu,
+	}
+
+	if params.WebGL2 != nil {
+		webgl2 := *params.WebGL2
+		webgl2.Extensions = append([]string(nil), params.WebGL2.Extensions...)
+		profile.WebGL2 = &webgl2
+	}
+
+	if browser == browserFirefox {
+		g.data.Firefox.apply(profile)
+	}
+
+	if params.WebGPU != nil {
+		available, err := g.data.WebGPU.available(platform, o)
+		if err != nil {
+			return nil, err
+		}
+		if available {
+			profile.WebGPU = params.WebGPU.profile(g.data.WebGPU.adapterInfoFor(latest))
+		}
+	}
+
+	return profile, nil
+}
+
+// angleUnmaskedVendor derives the UNMASKED_VENDOR_WEBGL value Chromium reports
+// for an ANGLE renderer, e.g. "Google Inc. (NVIDIA)".
+func angleUnmaskedVendor(renderer string) string {
+	inner, ok := strings.CutPrefix(renderer, "ANGLE (")
+	if !ok {
+		return ""
+	}
+	vendor, _, ok := strings.Cut(inner, ", ")
+	if !ok {
+		return "Google Inc."
+	}
+	return fmt.Sprintf("Google Inc. (%s)", vendor)
+}
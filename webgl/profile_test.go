@@ -0,0 +1,107 @@
+package webgl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfile(t *testing.T) {
+	tests := []struct {
+		name                   string
+		platform               string
+		platformVersion        string
+		opts                   []Option
+		expectedUnmaskedVendor string
+		expectedVersion        string
+		expectedExtension      string
+	}{
+		{
+			name:                   "macOS",
+			platform:               "macOS",
+			platformVersion:        "11.1",
+			expectedUnmaskedVendor: "Google Inc. (Apple)",
+			expectedVersion:        "WebGL 1.0 (OpenGL ES 2.0 Chromium)",
+			expectedExtension:      "WEBGL_compressed_texture_astc",
+		},
+		{
+			name:                   "Windows ARM64",
+			platform:               "Windows",
+			platformVersion:        "14.0.0",
+			opts:                   []Option{WithArch("arm")},
+			expectedUnmaskedVendor: "Google Inc. (Qualcomm)",
+			expectedVersion:        "WebGL 1.0 (OpenGL ES 2.0 Chromium)",
+			expectedExtension:      "WEBGL_compressed_texture_s3tc",
+		},
+		{
+			name:                   "iOS",
+			platform:               "iOS",
+			platformVersion:        "17.6.1",
+			expectedUnmaskedVendor: "Apple Inc.",
+			expectedVersion:        "WebGL 1.0",
+			expectedExtension:      "WEBKIT_WEBGL_compressed_texture_pvrtc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := GenerateProfile(12345, tt.platform, tt.platformVersion, tt.opts...)
+			require.NoError(t, err)
+
+			renderer, err := GenerateRenderer(12345, tt.platform, tt.platformVersion, tt.opts...)
+			require.NoError(t, err)
+
+			require.Equal(t, renderer, profile.UnmaskedRenderer)
+			require.Equal(t, "WebKit", profile.Vendor)
+			require.Equal(t, "WebKit WebGL", profile.Renderer)
+			require.Equal(t, tt.expectedUnmaskedVendor, profile.UnmaskedVendor)
+			require.Equal(t, tt.expectedVersion, profile.Version)
+			require.NotEmpty(t, profile.ShadingLanguageVersion)
+			require.Positive(t, profile.MaxTextureSize)
+			require.Positive(t, profile.MaxViewportDims[0])
+			require.Contains(t, profile.Extensions, "WEBGL_debug_renderer_info")
+			require.Contains(t, profile.Extensions, tt.expectedExtension)
+			require.Equal(t, 23, profile.PrecisionFormats.Fragment.HighFloat.Precision)
+		})
+	}
+}
+
+func TestParametersFor(t *testing.T) {
+	tests := []struct {
+		renderer       string
+		expectedName   string
+		maxTextureSize int
+	}{
+		{"ANGLE (NVIDIA, NVIDIA GeForce RTX 3060 (0x00002503) Direct3D11 vs_5_0 ps_5_0, D3D11)", "d3d11", 16384},
+		{"ANGLE (NVIDIA GeForce GTX 670 Direct3D9Ex vs_0_0 ps_2_0)", "d3d9", 8192},
+		{"ANGLE (NVIDIA Corporation, NVIDIA GeForce GTX 1080/PCIe/SSE2, OpenGL 4.5.0)", "nvidia-opengl", 32768},
+		{"ANGLE (Intel, Mesa Intel(R) UHD Graphics 620 (KBL GT2), OpenGL 4.6)", "opengl", 16384},
+		{"ANGLE (ARM, Mali-G68, OpenGL ES 3.2)", "mali", 8192},
+		{"Apple GPU", "safari", 16384},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedName, func(t *testing.T) {
+			params, ok := data.parametersFor(tt.renderer)
+			require.True(t, ok)
+			require.Equal(t, tt.expectedName, params.Name)
+			require.Equal(t, tt.maxTextureSize, params.MaxTextureSize)
+		})
+	}
+}
+
+func TestAngleUnmaskedVendor(t *testing.T) {
+	require.Equal(t, "Google Inc. (NVIDIA Corporation)", angleUnmaskedVendor("ANGLE (NVIDIA Corporation, GeForce GTX 1050/PCIe/SSE2, OpenGL 4.5 core)"))
+	require.Equal(t, "Google Inc.", angleUnmaskedVendor("ANGLE (AMD Radeon R7 430 Direct3D11 vs_5_0 ps_5_0)"))
+	require.Equal(t, "", angleUnmaskedVendor("Apple GPU"))
+}
+
+func TestGenerateProfileWithChromeVersion(t *testing.T) {
+	profile, err := GenerateProfile(12345, "Linux", "6.10.5", WithBrowser("Chrome", "96.0.4664.110"), WithVendors("intel"), WithBackends("opengl"))
+	require.NoError(t, err)
+
+	require.NotContains(t, profile.UnmaskedRenderer, "ANGLE")
+	require.Contains(t, []string{"Intel", "Intel Open Source Technology Center"}, profile.UnmaskedVendor)
+	require.Equal(t, profile.UnmaskedRenderer, profile.GPU.Renderer)
+	require.Positive(t, profile.MaxTextureSize)
+}
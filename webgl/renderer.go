@@ -1,9 +1,12 @@
 package webgl
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/go-version"
@@ -14,68 +17,286 @@ var (
 	ErrUnsupportedPlatform    = fmt.Errorf("unsupported platform")
 	ErrInvalidPlatformVersion = fmt.Errorf("invalid platform version")
 	ErrNoCompatibleRenderer   = fmt.Errorf("no compatible renderer found")
+	ErrNoParameters           = fmt.Errorf("no parameters found for renderer")
+	ErrInvalidBrowserVersion  = fmt.Errorf("invalid browser version")
+	ErrInvalidCatalog         = fmt.Errorf("invalid renderer catalog")
 )
 
 //go:embed data.yml
 var dataFile embed.FS
 
 type RendererData struct {
-	MacOS   map[string][]string `yaml:"macOS"`
-	Linux   map[string][]string `yaml:"Linux"`
-	Windows map[string][]string `yaml:"Windows"`
+	MacOS    map[string][]GPU `yaml:"macOS"`
+	Linux    map[string][]GPU `yaml:"Linux"`
+	Windows  map[string][]GPU `yaml:"Windows"`
+	Android  map[string][]GPU `yaml:"Android"`
+	IOS      map[string][]GPU `yaml:"iOS"`
+	ChromeOS map[string][]GPU `yaml:"Chrome OS"`
+	IPadOS   map[string][]GPU `yaml:"iPadOS"`
+
+	// ARM renderers for platforms where x86 is the default architecture
+	WindowsARM map[string][]GPU `yaml:"Windows arm"`
+	LinuxARM   map[string][]GPU `yaml:"Linux arm"`
+
+	Vendors    map[string]string             `yaml:"vendors"`
+	Templates  map[string][]RendererTemplate `yaml:"templates"`
+	Firefox    FirefoxData                   `yaml:"firefox"`
+	Safari     SafariData                    `yaml:"safari"`
+	Parameters []Parameters                  `yaml:"parameters"`
+	WebGPU     WebGPUData                    `yaml:"webgpu"`
 }
 
+// data is the embedded catalog used by the package level functions.
 var data RendererData
 
+var defaultGenerator = &Generator{data: &data}
+
 func init() {
 	yamlData, err := dataFile.ReadFile("data.yml")
 	if err != nil {
 		panic(fmt.Sprintf("Failed to read data.yml: %v", err))
 	}
 
-	err = yaml.Unmarshal(yamlData, &data)
+	data, err = loadRendererData(yamlData)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to unmarshal YAML data: %v", err))
+		panic(fmt.Sprintf("Failed to load data.yml: %v", err))
+	}
+}
+
+// loadRendererData decodes a renderer catalog and prepares it for generation.
+// Unknown fields and invalid values are reported together, each with its line number.
+func loadRendererData(b []byte) (RendererData, error) {
+	root, err := parseCatalog(b)
+	if err != nil {
+		return RendererData{}, err
+	}
+	return newRendererData(root, nil)
+}
+
+// parseCatalog parses a catalog document, rejecting unknown fields and values of the wrong type.
+func parseCatalog(b []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+	if root.Kind == 0 {
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&RendererData{}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+	return &root, nil
+}
+
+// newRendererData decodes a parsed catalog and prepares it for generation. sources names the
+// catalog each node comes from, if the catalog was merged from several ones.
+func newRendererData(root *yaml.Node, sources map[*yaml.Node]string) (RendererData, error) {
+	var d RendererData
+	if err := root.Decode(&d); err != nil {
+		return RendererData{}, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+
+	errs := &catalogErrors{root: root, sources: sources}
+	d.renderGPUs(errs)
+	d.compileParameters(errs)
+	d.WebGPU.compileAdapters(errs)
+	if err := errs.err(); err != nil {
+		return RendererData{}, err
 	}
+	return d, nil
+}
+
+type options struct {
+	Arch           string
+	Browser        string
+	BrowserVersion string
+	Filters        []func(*GPU) bool
+	// RendererFilters match the renderer reported for the requested browser version.
+	RendererFilters []func(string) bool
+	Weight          func(GPU) int
 }
 
-func GenerateRenderer(seed int64, platform string, platformVersion string) (string, error) {
-	r := rand.New(rand.NewSource(seed))
+type Option func(*options)
+
+// WithArch selects renderers for the given sec-ch-ua-arch value.
+// Platforms without a dedicated list for the architecture use their default renderers.
+func WithArch(arch string) Option {
+	return func(o *options) {
+		o.Arch = arch
+	}
+}
+
+// WithBrowser sets the browser name and version the renderer is reported by, e.g. "Chrome" and "129.0.0.0".
+// The name selects how the renderer is reported ("Chrome", "Firefox" or "Safari", other browsers are
+// treated as Chromium) and the version selects the format of that release. Without it, the latest Chrome is assumed.
+func WithBrowser(name, version string) Option {
+	return func(o *options) {
+		o.Browser = name
+		o.BrowserVersion = version
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// GenerateRenderer generates a renderer from the embedded catalog.
+func GenerateRenderer(seed int64, platform string, platformVersion string, opts ...Option) (string, error) {
+	return defaultGenerator.GenerateRenderer(seed, platform, platformVersion, opts...)
+}
+
+// GenerateGPU picks a GPU the same way GenerateRenderer does and returns its catalog entry.
+func GenerateGPU(seed int64, platform string, platformVersion string, opts ...Option) (GPU, error) {
+	return defaultGenerator.GenerateGPU(seed, platform, platformVersion, opts...)
+}
+
+// GenerateRenderer generates a renderer from the generator's catalog.
+func (g *Generator) GenerateRenderer(seed int64, platform string, platformVersion string, opts ...Option) (string, error) {
+	gpu, err := g.data.generateGPU(seed, platform, platformVersion, newOptions(opts))
+	if err != nil {
+		return "", err
+	}
+	return gpu.Renderer, nil
+}
+
+// GenerateGPU picks a GPU the same way GenerateRenderer does and returns its catalog entry.
+func (g *Generator) GenerateGPU(seed int64, platform string, platformVersion string, opts ...Option) (GPU, error) {
+	gpu, err := g.data.generateGPU(seed, platform, platformVersion, newOptions(opts))
+	if err != nil {
+		return GPU{}, err
+	}
+	return *gpu, nil
+}
+
+func (d *RendererData) generateGPU(seed int64, platform string, platformVersion string, o options) (*GPU, error) {
+	browser, major, err := o.browser()
+	if err != nil {
+		return nil, err
+	}
+
+	o.Filters = append(o.Filters[:len(o.Filters):len(o.Filters)], func(gpu *GPU) bool {
+		renderer, ok := d.renderer(gpu, browser, major)
+		if !ok {
+			return false
+		}
+		for _, keep := range o.RendererFilters {
+			if !keep(renderer) {
+				return false
+			}
+		}
+		return true
+	})
+
+	gpu, err := d.selectGPU(rand.New(rand.NewSource(seed)), platform, platformVersion, o)
+	if err != nil {
+		return nil, err
+	}
+
+	versioned := *gpu
+	versioned.Renderer, _ = d.renderer(gpu, browser, major)
+	return &versioned, nil
+}
+
+func (d *RendererData) selectGPU(r *rand.Rand, platform string, platformVersion string, o options) (*GPU, error) {
+	if strings.ToLower(o.Arch) == "arm" {
+		switch strings.ToLower(platform) {
+		case "linux":
+			return generateVersionedGPU(r, d.LinuxARM, platformVersion, o)
+		case "windows":
+			return generateVersionedGPU(r, d.WindowsARM, platformVersion, o)
+		}
+	}
 
 	switch strings.ToLower(platform) {
 	case "macos":
-		return generateVersionedRenderer(r, data.MacOS, platformVersion)
+		return generateVersionedGPU(r, d.MacOS, platformVersion, o)
 	case "linux":
-		return generateVersionedRenderer(r, data.Linux, platformVersion)
+		return generateVersionedGPU(r, d.Linux, platformVersion, o)
 	case "windows":
-		return generateVersionedRenderer(r, data.Windows, platformVersion)
+		return generateVersionedGPU(r, d.Windows, platformVersion, o)
+	case "android":
+		return generateVersionedGPU(r, d.Android, platformVersion, o)
+	case "ios":
+		return generateVersionedGPU(r, d.IOS, platformVersion, o)
+	case "chrome os", "chromeos":
+		return generateVersionedGPU(r, d.ChromeOS, platformVersion, o)
+	case "ipados":
+		return generateVersionedGPU(r, d.IPadOS, platformVersion, o)
 	default:
-		return "", fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, platform)
 	}
 }
 
-func generateVersionedRenderer(r *rand.Rand, versionedRenderers map[string][]string, platformVersion string) (string, error) {
-	var compatibleRenderers []string
+func generateVersionedGPU(r *rand.Rand, versionedGPUs map[string][]GPU, platformVersion string, o options) (*GPU, error) {
+	var compatibleGPUs []*GPU
+	var weights []int
+	totalWeight := 0
 
-	for versionStr, renderers := range versionedRenderers {
-		v1, err := version.NewVersion(versionStr)
-		if err != nil {
-			panic(fmt.Sprintf("invalid version in data.yml: %s", versionStr))
-		}
+	v, err := version.NewVersion(platformVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPlatformVersion, platformVersion)
+	}
 
-		v2, err := version.NewVersion(platformVersion)
-		if err != nil {
-			return "", fmt.Errorf("%w: %s", ErrInvalidPlatformVersion, platformVersion)
+	// Map iteration order is random, the keys are sorted for a seed to always pick the same GPU.
+	for _, key := range slices.Sorted(maps.Keys(versionedGPUs)) {
+		gpus := versionedGPUs[key]
+		for i := range gpus {
+			if !gpus[i].platformVersions.Check(v) || !keepGPU(&gpus[i], o.Filters) {
+				continue
+			}
+			weight := gpus[i].Weight
+			if o.Weight != nil {
+				weight = o.Weight(gpus[i])
+			}
+			if weight <= 0 {
+				continue
+			}
+			compatibleGPUs = append(compatibleGPUs, &gpus[i])
+			weights = append(weights, weight)
+			totalWeight += weight
 		}
+	}
 
-		if v2.GreaterThanOrEqual(v1) {
-			compatibleRenderers = append(compatibleRenderers, renderers...)
+	if len(compatibleGPUs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCompatibleRenderer, platformVersion)
+	}
+
+	n := r.Intn(totalWeight)
+	for i, gpu := range compatibleGPUs {
+		n -= weights[i]
+		if n < 0 {
+			return gpu, nil
 		}
 	}
+	return compatibleGPUs[len(compatibleGPUs)-1], nil
+}
 
-	if len(compatibleRenderers) == 0 {
-		return "", fmt.Errorf("%w: %s", ErrNoCompatibleRenderer, platformVersion)
+// parseVersionRange parses a catalog key, either a minimum platform version such as "12.4"
+// or a constraint such as ">= 12.4, < 15".
+func parseVersionRange(key string) (version.Constraints, error) {
+	constraint := key
+	if _, err := version.NewVersion(key); err == nil {
+		constraint = ">= " + key
+	}
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform version range %q: %w", key, err)
 	}
+	return constraints, nil
+}
 
-	return compatibleRenderers[r.Intn(len(compatibleRenderers))], nil
+func keepGPU(gpu *GPU, filters []func(*GPU) bool) bool {
+	for _, keep := range filters {
+		if !keep(gpu) {
+			return false
+		}
+	}
+	return true
 }
@@ -1,9 +1,14 @@
 package webgl
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/go-version"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,7 +26,7 @@ func TestGenerateRenderer(t *testing.T) {
 			seed:            12345,
 			platform:        "macOS",
 			platformVersion: "11.1",
-			expectedPrefix:  "ANGLE (Apple, Apple M1",
+			expectedPrefix:  "ANGLE (Apple, ANGLE Metal Renderer: Apple M1",
 		},
 		{
 			name:            "valid Linux",
@@ -37,10 +42,38 @@ func TestGenerateRenderer(t *testing.T) {
 			platformVersion: "14.0.0.0",
 			expectedPrefix:  "ANGLE (",
 		},
+		{
+			name:            "valid Android",
+			seed:            12345,
+			platform:        "Android",
+			platformVersion: "14.0.0",
+			expectedPrefix:  "ANGLE (",
+		},
+		{
+			name:            "valid iOS",
+			seed:            12345,
+			platform:        "iOS",
+			platformVersion: "17.6.1",
+			expectedPrefix:  "Apple GPU",
+		},
+		{
+			name:            "valid Chrome OS",
+			seed:            12345,
+			platform:        "Chrome OS",
+			platformVersion: "16002.44.0",
+			expectedPrefix:  "ANGLE (",
+		},
+		{
+			name:            "valid iPadOS",
+			seed:            12345,
+			platform:        "iPadOS",
+			platformVersion: "18.0",
+			expectedPrefix:  "Apple GPU",
+		},
 		{
 			name:            "unsupported platform",
 			seed:            22222,
-			platform:        "Android",
+			platform:        "PlayStation",
 			platformVersion: "11",
 			expectedError:   ErrUnsupportedPlatform,
 		},
@@ -63,7 +96,7 @@ func TestGenerateRenderer(t *testing.T) {
 			seed:            55555,
 			platform:        "MACOS",
 			platformVersion: "11.1",
-			expectedPrefix:  "ANGLE (Apple, Apple M1",
+			expectedPrefix:  "ANGLE (Apple, ANGLE Metal Renderer: Apple M1",
 		},
 	}
 
@@ -82,6 +115,111 @@ func TestGenerateRenderer(t *testing.T) {
 	}
 }
 
+func TestGenerateRendererWithArch(t *testing.T) {
+	tests := []struct {
+		name            string
+		platform        string
+		platformVersion string
+		arch            string
+		expectedError   error
+		expectedPrefix  string
+	}{
+		{
+			name:            "Windows ARM64",
+			platform:        "Windows",
+			platformVersion: "14.0.0",
+			arch:            "arm",
+			expectedPrefix:  "ANGLE (Qualcomm, ",
+		},
+		{
+			name:            "Windows 10 ARM64",
+			platform:        "Windows",
+			platformVersion: "10.0.0",
+			arch:            "arm",
+			expectedError:   ErrNoCompatibleRenderer,
+		},
+		{
+			name:            "Windows WoW64",
+			platform:        "Windows",
+			platformVersion: "14.0.0",
+			arch:            "x86",
+			expectedPrefix:  "ANGLE (",
+		},
+		{
+			name:            "Linux aarch64",
+			platform:        "Linux",
+			platformVersion: "6.10.11",
+			arch:            "arm",
+			expectedPrefix:  "ANGLE (",
+		},
+		{
+			name:            "macOS arm",
+			platform:        "macOS",
+			platformVersion: "14.7",
+			arch:            "arm",
+			expectedPrefix:  "ANGLE (Apple, ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := GenerateRenderer(12345, tt.platform, tt.platformVersion, WithArch(tt.arch))
+
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(renderer, tt.expectedPrefix), "Renderer should start with expected prefix")
+			}
+		})
+	}
+}
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		key           string
+		matching      []string
+		notMatching   []string
+		expectedError bool
+	}{
+		{key: "12.4", matching: []string{"12.4", "12.4.1", "15.0"}, notMatching: []string{"11.7", "12.3.9"}},
+		{key: "0.0.0", matching: []string{"0.0.0", "6.10.5"}},
+		{key: ">= 12.4, < 15", matching: []string{"12.4", "14.7.1"}, notMatching: []string{"12.3", "15.0", "15.1"}},
+		{key: "< 13", matching: []string{"0.0.0", "10.0.0", "12.9"}, notMatching: []string{"13.0.0", "15.0.0"}},
+		{key: "latest", expectedError: true},
+		{key: ">= 12.4,", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			constraints, err := parseVersionRange(tt.key)
+			if tt.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, v := range tt.matching {
+				require.True(t, constraints.Check(version.Must(version.NewVersion(v))), v)
+			}
+			for _, v := range tt.notMatching {
+				require.False(t, constraints.Check(version.Must(version.NewVersion(v))), v)
+			}
+		})
+	}
+}
+
+func TestGenerateRendererRetiredGPUs(t *testing.T) {
+	retired := WithRendererMatch(regexp.MustCompile(`HD Graphics 3000|GeForce 210`))
+
+	_, err := GenerateRenderer(1, "Windows", "15.0.0", retired)
+	require.ErrorIs(t, err, ErrNoCompatibleRenderer)
+
+	renderer, err := GenerateRenderer(1, "Windows", "10.0.0", retired)
+	require.NoError(t, err)
+	require.Regexp(t, `HD Graphics 3000|GeForce 210`, renderer)
+}
+
 func TestGenerateRendererDeterministic(t *testing.T) {
 	seed := int64(99999)
 	platform := "macOS"
@@ -96,6 +234,34 @@ func TestGenerateRendererDeterministic(t *testing.T) {
 	require.Equal(t, renderer1, renderer2, "Renderers should be the same for the same seed")
 }
 
+// TestGenerateRendererRegression pins the renderers generated for a fixed set of seeds.
+// The hash only changes with the catalog or the selection algorithm, update it deliberately then.
+func TestGenerateRendererRegression(t *testing.T) {
+	platforms := []struct {
+		platform        string
+		platformVersion string
+	}{
+		{"macOS", "15.0"},
+		{"Windows", "14.0.0"},
+		{"Windows", "10.0.0"},
+		{"Linux", "6.10.5"},
+		{"Android", "14.0.0"},
+		{"iOS", "18.0"},
+		{"Chrome OS", "16002.44.0"},
+	}
+
+	h := sha256.New()
+	for _, p := range platforms {
+		for seed := int64(0); seed < 100; seed++ {
+			renderer, err := GenerateRenderer(seed, p.platform, p.platformVersion)
+			require.NoError(t, err)
+			fmt.Fprintf(h, "%s %s %d %s\n", p.platform, p.platformVersion, seed, renderer)
+		}
+	}
+
+	require.Equal(t, "dd50eee08f5aba497c29b6dad5d55a2f6069a22094d12adeb505e38cd46e2b30", hex.EncodeToString(h.Sum(nil)))
+}
+
 func TestGenerateRendererDifferentSeeds(t *testing.T) {
 	platform := "Linux"
 	platformVersion := "5.10.0"
@@ -0,0 +1,86 @@
+package webgl
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// catalogErrors collects the problems found while loading a catalog so that they are
+// reported at once, each with the line of the offending value and, for merged catalogs,
+// the name of the catalog defining it.
+type catalogErrors struct {
+	root    *yaml.Node
+	sources map[*yaml.Node]string
+	errs    []error
+}
+
+// add records a problem with the value found at path, see nodeAt.
+func (e *catalogErrors) add(err error, path ...any) {
+	node := nodeAt(e.root, path...)
+	line := 0
+	if node != nil {
+		line = node.Line
+	}
+	if name, ok := e.sources[node]; ok {
+		e.errs = append(e.errs, fmt.Errorf("%s:%d: %w", name, line, err))
+		return
+	}
+	e.errs = append(e.errs, fmt.Errorf("line %d: %w", line, err))
+}
+
+func (e *catalogErrors) err() error {
+	if len(e.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidCatalog, errors.Join(e.errs...))
+}
+
+// nodeAt follows mapping keys (strings) and sequence indexes (ints) from the document root
+// and returns the last key or item found.
+func nodeAt(root *yaml.Node, path ...any) *yaml.Node {
+	if root == nil {
+		return nil
+	}
+
+	node, found := root, root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, p := range path {
+		next := (*yaml.Node)(nil)
+		switch p := p.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return found
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == p {
+					found, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+		case int:
+			if node.Kind != yaml.SequenceNode || p >= len(node.Content) {
+				return found
+			}
+			next = node.Content[p]
+			found = next
+		}
+		if next == nil {
+			return found
+		}
+		node = next
+	}
+	return found
+}
+
+// recordSource maps every node of the catalog to the name of its source.
+func recordSource(sources map[*yaml.Node]string, node *yaml.Node, name string) {
+	sources[node] = name
+	for _, child := range node.Content {
+		recordSource(sources, child, name)
+	}
+}
@@ -0,0 +1,179 @@
+package webgl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRendererData(t *testing.T) {
+	tests := []struct {
+		name           string
+		yaml           string
+		expectedErrors []string
+	}{
+		{
+			name: "valid",
+			yaml: `
+Windows:
+  0.0.0:
+    - {vendor: nvidia, model: 'NVIDIA GeForce RTX 4090', backend: d3d11, tier: high}
+vendors:
+  nvidia: NVIDIA
+templates:
+  d3d11:
+    - template: 'ANGLE ({{.Vendor}}, {{.Device}} Direct3D11 vs_{{.ShaderModel}} ps_{{.ShaderModel}})'
+`,
+		},
+		{
+			name: "all problems",
+			yaml: `
+Windows:
+  '>= 10,':
+    - {vendor: nvidia, model: 'NVIDIA GeForce RTX 4090', backend: d3d11, tier: high}
+  0.0.0:
+    - {vendor: nvidia, model: 'NVIDIA GeForce RTX 4080', backend: glide, tier: high}
+    - {vendor: 3dfx, model: 'Voodoo3', backend: d3d11, tier: best}
+vendors:
+  nvidia: NVIDIA
+templates:
+  d3d11:
+    - template: 'ANGLE ({{.Vendor}}, {{.Device}} Direct3D11 vs_{{.ShaderModel}} ps_{{.ShaderModel}})'
+    - since: 94
+      template: 'ANGLE ({{.Vendor}'
+parameters:
+  - name: broken
+    match: '('
+`,
+			expectedErrors: []string{
+				`line 3: invalid platform version range ">= 10,"`,
+				`line 6: gpu "NVIDIA GeForce RTX 4080": no template for backend "glide"`,
+				`line 7: gpu "Voodoo3": invalid tier "best"`,
+				`line 7: gpu "Voodoo3": unknown vendor "3dfx"`,
+				`line 14: template "d3d11" since 94`,
+				`line 17: parameters "broken"`,
+			},
+		},
+		{
+			name: "unknown field",
+			yaml: `
+Linux:
+  0.0.0:
+    - {vendor: intel, model: 'Intel(R) Graphics', backend: opengl, tire: low}
+`,
+			expectedErrors: []string{"line 4: field tire not found"},
+		},
+		{
+			name:           "malformed yaml",
+			yaml:           "Linux: [",
+			expectedErrors: []string{"line 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadRendererData([]byte(tt.yaml))
+			if len(tt.expectedErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidCatalog)
+			for _, expected := range tt.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+// TestCatalogLint checks the embedded catalog for entries that load fine but would
+// generate duplicated or implausible values.
+func TestCatalogLint(t *testing.T) {
+	for platform, versioned := range data.platformGPUs() {
+		seen := map[string]string{}
+		for key, gpus := range versioned {
+			for _, gpu := range gpus {
+				var renderers []string
+				for _, r := range gpu.renderers {
+					renderers = append(renderers, r.renderer)
+					require.NoError(t, lintRenderer(r.renderer), "%s %s: %s", platform, key, gpu.Model)
+				}
+
+				id := strings.Join(append(renderers, gpu.Backend), "\n")
+				if other, ok := seen[id]; ok {
+					require.Failf(t, "duplicate GPU", "%s: %s is listed under %s and %s", platform, gpu.Model, other, key)
+				}
+				seen[id] = key
+
+				latest, _ := data.renderer(&gpu, browserChrome, 0)
+				_, ok := data.parametersFor(latest)
+				require.True(t, ok, "%s %s: no parameters for %s", platform, key, latest)
+			}
+		}
+	}
+}
+
+func TestLintRenderer(t *testing.T) {
+	tests := []struct {
+		renderer      string
+		expectedError bool
+	}{
+		{renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090 (0x00002684) Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+		{renderer: "Mesa DRI Intel(R) HD Graphics (Coffeelake 3x8 GT2) "},
+		{renderer: "Apple GPU"},
+		{renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090 Direct3D11 vs_5_0 ps_5_0, D3D11", expectedError: true},
+		{renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 4090)) Direct3D11 (", expectedError: true},
+		{renderer: "ANGLE (NVIDIA, , D3D11)", expectedError: true},
+		{renderer: "ANGLE (NVIDIA,  NVIDIA GeForce RTX 4090, D3D11)", expectedError: true},
+		{renderer: "ANGLE (NVIDIA, <no value>, D3D11)", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.renderer, func(t *testing.T) {
+			err := lintRenderer(tt.renderer)
+			if tt.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// lintRenderer reports ANGLE renderer strings that no real browser produces.
+func lintRenderer(renderer string) error {
+	if !strings.HasPrefix(renderer, "ANGLE (") {
+		return nil
+	}
+	if !strings.HasSuffix(renderer, ")") {
+		return fmt.Errorf("unterminated ANGLE renderer %q", renderer)
+	}
+
+	depth := 0
+	for i, c := range renderer {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 && i != len(renderer)-1 && i > len("ANGLE ") {
+			return fmt.Errorf("unbalanced parentheses in %q", renderer)
+		}
+		if depth < 0 {
+			return fmt.Errorf("unbalanced parentheses in %q", renderer)
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses in %q", renderer)
+	}
+
+	for _, malformed := range []string{", ,", "(,", ",)", "  ", "<no value>"} {
+		if strings.Contains(renderer, malformed) {
+			return fmt.Errorf("malformed ANGLE renderer %q", renderer)
+		}
+	}
+	return nil
+}
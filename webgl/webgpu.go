@@ -0,0 +1,93 @@
+package webgl
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// WebGPUProfile describes the adapter returned by navigator.gpu.requestAdapter().
+type WebGPUProfile struct {
+	Info     WebGPUAdapterInfo
+	Features []string
+	Limits   map[string]int64
+}
+
+// WebGPUAdapterInfo mirrors GPUAdapterInfo. Chrome leaves Device and Description empty
+// unless the user enables WebGPU developer features.
+type WebGPUAdapterInfo struct {
+	Vendor       string
+	Architecture string
+	Device       string
+	Description  string
+}
+
+// WebGPUParameters holds the adapter capabilities shared by the renderers of a parameter set.
+type WebGPUParameters struct {
+	Features []string         `yaml:"features"`
+	Limits   map[string]int64 `yaml:"limits"`
+}
+
+func (p *WebGPUParameters) profile(info WebGPUAdapterInfo) *WebGPUProfile {
+	return &WebGPUProfile{
+		Info:     info,
+		Features: append([]string(nil), p.Features...),
+		Limits:   maps.Clone(p.Limits),
+	}
+}
+
+type WebGPUData struct {
+	Since    map[string]int  `yaml:"since"`
+	Adapters []WebGPUAdapter `yaml:"adapters"`
+}
+
+type WebGPUAdapter struct {
+	Match        string `yaml:"match"`
+	Vendor       string `yaml:"vendor"`
+	Architecture string `yaml:"architecture"`
+
+	match *regexp.Regexp
+}
+
+func (d *WebGPUData) compileAdapters(errs *catalogErrors) {
+	for i := range d.Adapters {
+		re, err := regexp.Compile(d.Adapters[i].Match)
+		if err != nil {
+			errs.add(fmt.Errorf("adapter %q: %w", d.Adapters[i].Match, err), "webgpu", "adapters", i, "match")
+			continue
+		}
+		d.Adapters[i].match = re
+	}
+}
+
+func (d *WebGPUData) adapterInfoFor(renderer string) WebGPUAdapterInfo {
+	for _, adapter := range d.Adapters {
+		if adapter.match.MatchString(renderer) {
+			return WebGPUAdapterInfo{
+				Vendor:       adapter.Vendor,
+				Architecture: adapter.Architecture,
+			}
+		}
+	}
+	return WebGPUAdapterInfo{}
+}
+
+// available reports whether the browser exposes WebGPU on the platform by default.
+func (d *WebGPUData) available(platform string, o options) (bool, error) {
+	browser, chrome, err := o.browser()
+	if err != nil {
+		return false, err
+	}
+	if browser != browserChrome {
+		return false, nil
+	}
+
+	for name, since := range d.Since {
+		if strings.EqualFold(name, platform) {
+			return chrome == 0 || chrome >= since, nil
+		}
+	}
+
+	return false, nil
+}
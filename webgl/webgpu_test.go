@@ -0,0 +1,163 @@
+package webgl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateProfileWebGPU(t *testing.T) {
+	tests := []struct {
+		name            string
+		platform        string
+		platformVersion string
+		opts            []Option
+		expectedWebGL2  string
+		expectedVendor  string
+		expectedWebGPU  bool
+		expectedErr     error
+	}{
+		{
+			name:            "Windows ARM64 Chrome 129",
+			platform:        "Windows",
+			platformVersion: "14.0.0",
+			opts:            []Option{WithArch("arm"), WithBrowser("Chrome", "129.0.0.0")},
+			expectedWebGL2:  "WebGL 2.0 (OpenGL ES 3.0 Chromium)",
+			expectedVendor:  "qualcomm",
+			expectedWebGPU:  true,
+		},
+		{
+			name:            "Windows ARM64 Chrome 112",
+			platform:        "Windows",
+			platformVersion: "14.0.0",
+			opts:            []Option{WithArch("arm"), WithBrowser("Chrome", "112.0.0.0")},
+			expectedWebGL2:  "WebGL 2.0 (OpenGL ES 3.0 Chromium)",
+		},
+		{
+			name:            "Android Chrome 120",
+			platform:        "Android",
+			platformVersion: "13.0.0",
+			opts:            []Option{WithBrowser("Chrome", "120.0.0.0")},
+			expectedWebGL2:  "WebGL 2.0 (OpenGL ES 3.0 Chromium)",
+		},
+		{
+			name:            "Android Chrome 121",
+			platform:        "Android",
+			platformVersion: "13.0.0",
+			opts:            []Option{WithBrowser("Chrome", "121.0.0.0")},
+			expectedWebGL2:  "WebGL 2.0 (OpenGL ES 3.0 Chromium)",
+			expectedWebGPU:  true,
+		},
+		{
+			name:            "Android latest Chrome",
+			platform:        "Android",
+			platformVersion: "13.0.0",
+			expectedWebGL2:  "WebGL 2.0 (OpenGL ES 3.0 Chromium)",
+			expectedWebGPU:  true,
+		},
+		{
+			name:            "Linux",
+			platform:        "Linux",
+			platformVersion: "6.10.5",
+			opts:            []Option{WithBrowser("Chrome", "129.0.0.0")},
+			expectedWebGL2:  "WebGL 2.0 (OpenGL ES 3.0 Chromium)",
+		},
+		{
+			name:            "iOS Safari",
+			platform:        "iOS",
+			platformVersion: "17.6.1",
+			opts:            []Option{WithBrowser("Safari", "17.6")},
+			expectedWebGL2:  "WebGL 2.0",
+		},
+		{
+			name:            "Invalid browser version",
+			platform:        "Android",
+			platformVersion: "13.0.0",
+			opts:            []Option{WithBrowser("Chrome", "latest")},
+			expectedErr:     ErrInvalidBrowserVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := GenerateProfile(12345, tt.platform, tt.platformVersion, tt.opts...)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NotNil(t, profile.WebGL2)
+			require.Equal(t, tt.expectedWebGL2, profile.WebGL2.Version)
+			require.Positive(t, profile.WebGL2.Max3DTextureSize)
+			require.NotEmpty(t, profile.WebGL2.Extensions)
+
+			if !tt.expectedWebGPU {
+				require.Nil(t, profile.WebGPU)
+				return
+			}
+			require.NotNil(t, profile.WebGPU)
+			require.NotEmpty(t, profile.WebGPU.Info.Vendor)
+			if tt.expectedVendor != "" {
+				require.Equal(t, tt.expectedVendor, profile.WebGPU.Info.Vendor)
+			}
+			require.Empty(t, profile.WebGPU.Info.Device)
+			require.NotEmpty(t, profile.WebGPU.Features)
+			require.Positive(t, profile.WebGPU.Limits["maxTextureDimension2D"])
+		})
+	}
+}
+
+func TestAdapterInfoFor(t *testing.T) {
+	tests := []struct {
+		renderer string
+		expected WebGPUAdapterInfo
+	}{
+		{
+			renderer: "ANGLE (NVIDIA, NVIDIA GeForce RTX 4070 (0x00002786) Direct3D11 vs_5_0 ps_5_0, D3D11)",
+			expected: WebGPUAdapterInfo{Vendor: "nvidia", Architecture: "lovelace"},
+		},
+		{
+			renderer: "ANGLE (NVIDIA, NVIDIA GeForce GTX 1060 6GB Direct3D11 vs_5_0 ps_5_0, D3D11)",
+			expected: WebGPUAdapterInfo{Vendor: "nvidia", Architecture: "pascal"},
+		},
+		{
+			renderer: "ANGLE (AMD, AMD Radeon RX 6600 XT Direct3D11 vs_5_0 ps_5_0, D3D11)",
+			expected: WebGPUAdapterInfo{Vendor: "amd", Architecture: "rdna-2"},
+		},
+		{
+			renderer: "ANGLE (Intel, Intel(R) UHD Graphics 630 Direct3D11 vs_5_0 ps_5_0, D3D11)",
+			expected: WebGPUAdapterInfo{Vendor: "intel", Architecture: "gen-9"},
+		},
+		{
+			renderer: "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)",
+			expected: WebGPUAdapterInfo{Vendor: "apple", Architecture: "metal-3"},
+		},
+		{
+			renderer: "ANGLE (Qualcomm, Adreno (TM) 740, OpenGL ES 3.2)",
+			expected: WebGPUAdapterInfo{Vendor: "qualcomm", Architecture: "adreno-7xx"},
+		},
+		{
+			renderer: "ANGLE (ARM, Mali-G715, OpenGL ES 3.2)",
+			expected: WebGPUAdapterInfo{Vendor: "arm", Architecture: "valhall"},
+		},
+		{
+			renderer: "ANGLE (ARM, Mali-G76 MC4, OpenGL ES 3.2)",
+			expected: WebGPUAdapterInfo{Vendor: "arm", Architecture: "bifrost"},
+		},
+		{
+			renderer: "ANGLE (ARM, Mali-T880, OpenGL ES 3.2)",
+			expected: WebGPUAdapterInfo{Vendor: "arm"},
+		},
+		{
+			renderer: "Apple GPU",
+			expected: WebGPUAdapterInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.renderer, func(t *testing.T) {
+			require.Equal(t, tt.expected, data.WebGPU.adapterInfoFor(tt.renderer))
+		})
+	}
+}